@@ -15,21 +15,6 @@ document.addEventListener('DOMContentLoaded', function() {
     });
   }
 
-  // Highlight active page in navigation
-  const currentPath = window.location.pathname;
-  const navLinks = document.querySelectorAll('nav a');
-  
-  navLinks.forEach(link => {
-    // Get the link path
-    const linkPath = link.getAttribute('href');
-    
-    // Check if the current page path matches the link path
-    if (currentPath === linkPath || 
-        (linkPath !== '/' && currentPath.startsWith(linkPath))) {
-      link.classList.add('active');
-    }
-  });
-
   // Add copy button to code blocks
   const codeBlocks = document.querySelectorAll('pre code');
   codeBlocks.forEach(codeBlock => {
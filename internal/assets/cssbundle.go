@@ -0,0 +1,380 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// cssImportRe matches @import "file.css" media; and @import url(file.css) media;
+	cssImportRe = regexp.MustCompile(`@import\s+(?:url\(\s*['"]?([^'")]+?)['"]?\s*\)|['"]([^'"]+)['"])\s*([^;]*);`)
+
+	// cssURLRe matches url() references, quoted or not
+	cssURLRe = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+?)(['"]?)\s*\)`)
+
+	// cssCommentRe matches block comments
+	cssCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/`)
+)
+
+// BundleCSS inlines the @import chain of the stylesheet entry into a single
+// resource published at target (the entry path when empty). Imports are
+// resolved like Get: site files override theme files. Relative url()
+// references are rewritten to stay valid from the bundle location. With
+// sourceMap set, a source map is published next to the bundle.
+func (p *Pipeline) BundleCSS(entry, target string, sourceMap bool) (*Resource, error) {
+	entry = cleanName(entry)
+	if target == "" {
+		target = entry
+	}
+	target = cleanName(target)
+
+	key := fmt.Sprintf("bundlecss:%s:%s:%t", entry, target, sourceMap)
+	return p.cached(key, func() (*Resource, error) {
+		return p.bundleCSS(entry, nil, target, sourceMap)
+	})
+}
+
+// BundleCSSResource inlines the @import chain of a stylesheet resource,
+// such as the result of Concat or Minify, into a bundle published at the
+// resource's name. Imports are resolved relative to that name.
+func (p *Pipeline) BundleCSSResource(r *Resource, sourceMap bool) (*Resource, error) {
+	if path.Ext(r.name) != ".css" {
+		return nil, fmt.Errorf("bundleCSS: %q is not a stylesheet", r.name)
+	}
+
+	key := fmt.Sprintf("bundlecss:%s:%t", r.key, sourceMap)
+	return p.cached(key, func() (*Resource, error) {
+		return p.bundleCSS(r.name, r.content, r.name, sourceMap)
+	})
+}
+
+// bundleCSS bundles the stylesheet entry, whose content is read from the
+// source directories unless data is given
+func (p *Pipeline) bundleCSS(entry string, data []byte, target string, sourceMap bool) (*Resource, error) {
+	b := &cssBundler{
+		pipeline:  p,
+		target:    target,
+		entry:     entry,
+		entryData: data,
+		included:  make(map[string]bool),
+		stack:     make(map[string]bool),
+	}
+	if err := b.include(entry, ""); err != nil {
+		return nil, err
+	}
+
+	r := &Resource{pipeline: p, name: target}
+	body := b.out.String()
+
+	// External imports must precede all other rules
+	if len(b.external) > 0 {
+		body = strings.Join(b.external, "\n") + "\n" + body
+		b.out.shiftLines(len(b.external))
+	}
+
+	if sourceMap {
+		mapName := target + ".map"
+		mapData, err := b.sourceMap(mapName)
+		if err != nil {
+			return nil, err
+		}
+		body += fmt.Sprintf("/*# sourceMappingURL=%s */\n", path.Base(mapName))
+		r.related = append(r.related, &Resource{pipeline: p, name: mapName, content: mapData})
+	}
+
+	r.content = []byte(body)
+	return r, nil
+}
+
+// cssBundler accumulates the output of a single bundle
+type cssBundler struct {
+	pipeline *Pipeline
+	target   string
+	// entry is the stylesheet the bundle starts from; entryData is its
+	// content when it isn't read from a file
+	entry     string
+	entryData []byte
+	out       mappedWriter
+	sources   []string
+	contents  []string
+	external  []string
+	included  map[string]bool
+	stack     map[string]bool
+}
+
+// resolve finds the file backing a virtual stylesheet path
+func (b *cssBundler) resolve(name string) (string, []byte, error) {
+	if name == b.entry && b.entryData != nil {
+		return name, b.entryData, nil
+	}
+	for _, dir := range b.pipeline.sourceDirs() {
+		srcPath := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(srcPath)
+		if err == nil {
+			return srcPath, data, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, err
+		}
+	}
+	return "", nil, fmt.Errorf("stylesheet %q not found", name)
+}
+
+// include writes a stylesheet and, recursively, its imports
+func (b *cssBundler) include(name, importedFrom string) error {
+	if b.stack[name] {
+		return fmt.Errorf("circular @import of %q from %q", name, importedFrom)
+	}
+	if b.included[name] {
+		// Each file is only included once per bundle
+		return nil
+	}
+
+	_, data, err := b.resolve(name)
+	if err != nil {
+		if importedFrom != "" {
+			return fmt.Errorf("%s: %w", importedFrom, err)
+		}
+		return err
+	}
+
+	b.included[name] = true
+	b.stack[name] = true
+	defer delete(b.stack, name)
+
+	src := len(b.sources)
+	b.sources = append(b.sources, name)
+	b.contents = append(b.contents, string(data))
+
+	text := b.rewriteURLs(string(data), name)
+	comments := cssCommentRe.FindAllStringIndex(text, -1)
+
+	pos, line := 0, 0
+	for _, m := range cssImportRe.FindAllStringSubmatchIndex(text, -1) {
+		if insideRanges(m[0], comments) {
+			continue
+		}
+
+		// Text preceding the import
+		before := text[pos:m[0]]
+		b.out.write(before, src, line)
+		line += strings.Count(before, "\n")
+
+		ref := submatch(text, m, 1)
+		if ref == "" {
+			ref = submatch(text, m, 2)
+		}
+		media := strings.TrimSpace(submatch(text, m, 3))
+		statement := text[m[0]:m[1]]
+
+		if isExternalURL(ref) {
+			b.external = append(b.external, statement)
+		} else {
+			imported := path.Join(path.Dir(name), ref)
+			if strings.HasPrefix(ref, "/") {
+				imported = cleanName(ref)
+			}
+
+			if media != "" {
+				b.out.write(fmt.Sprintf("@media %s {\n", media), src, line)
+			}
+			if err := b.include(imported, name); err != nil {
+				return err
+			}
+			if media != "" {
+				b.out.write("}\n", src, line)
+			}
+		}
+
+		// Drop the line break ending the statement along with it
+		end := m[1]
+		if strings.HasPrefix(text[end:], "\r\n") {
+			end += 2
+		} else if strings.HasPrefix(text[end:], "\n") {
+			end++
+		}
+		line += strings.Count(text[m[0]:end], "\n")
+		pos = end
+	}
+
+	rest := text[pos:]
+	b.out.write(rest, src, line)
+	if !strings.HasSuffix(rest, "\n") {
+		b.out.write("\n", src, line+strings.Count(rest, "\n"))
+	}
+
+	return nil
+}
+
+// rewriteURLs rewrites relative url() references in a stylesheet so they
+// resolve from the bundle's location instead of the original file's
+func (b *cssBundler) rewriteURLs(text, name string) string {
+	return cssURLRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := cssURLRe.FindStringSubmatch(match)
+		quote, ref := parts[1], parts[2]
+		if isExternalURL(ref) || strings.HasPrefix(ref, "/") ||
+			strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+
+		// Imported file paths are handled by include, not rewritten here
+		if strings.HasSuffix(strings.ToLower(strings.SplitN(ref, "?", 2)[0]), ".css") {
+			return match
+		}
+
+		resolved := path.Join(path.Dir(name), ref)
+		rewritten := relativePath(path.Dir(b.target), resolved)
+		return "url(" + quote + rewritten + quote + ")"
+	})
+}
+
+// sourceMap renders a version 3 source map for the bundle
+func (b *cssBundler) sourceMap(mapName string) ([]byte, error) {
+	sources := make([]string, len(b.sources))
+	for i, source := range b.sources {
+		sources[i] = relativePath(path.Dir(mapName), source)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"version":        3,
+		"file":           path.Base(b.target),
+		"sources":        sources,
+		"sourcesContent": b.contents,
+		"names":          []string{},
+		"mappings":       b.out.mappings(),
+	})
+}
+
+// mappedWriter collects output text along with the source line each output
+// line came from
+type mappedWriter struct {
+	sb        strings.Builder
+	lines     []lineMapping
+	lineStart bool
+}
+
+// lineMapping maps one output line to a line in a source file
+type lineMapping struct {
+	source int
+	line   int
+	mapped bool
+}
+
+// write appends text that starts at line of source src
+func (w *mappedWriter) write(text string, src, line int) {
+	if text == "" {
+		return
+	}
+	if len(w.lines) == 0 {
+		w.lines = append(w.lines, lineMapping{})
+		w.lineStart = true
+	}
+
+	for i := 0; i < len(text); i++ {
+		current := &w.lines[len(w.lines)-1]
+		if w.lineStart && !current.mapped {
+			*current = lineMapping{source: src, line: line, mapped: true}
+		}
+		w.lineStart = false
+
+		w.sb.WriteByte(text[i])
+		if text[i] == '\n' {
+			line++
+			w.lines = append(w.lines, lineMapping{})
+			w.lineStart = true
+		}
+	}
+}
+
+// String returns the written text
+func (w *mappedWriter) String() string {
+	return w.sb.String()
+}
+
+// shiftLines inserts n unmapped lines at the start of the output
+func (w *mappedWriter) shiftLines(n int) {
+	w.lines = append(make([]lineMapping, n), w.lines...)
+}
+
+// mappings encodes the line mappings as a source map "mappings" string
+func (w *mappedWriter) mappings() string {
+	var sb strings.Builder
+	prevSource, prevLine := 0, 0
+	for i, m := range w.lines {
+		if i > 0 {
+			sb.WriteByte(';')
+		}
+		if !m.mapped {
+			continue
+		}
+		// Segment: generated column, source index, source line, source column
+		sb.WriteString(encodeVLQ(0))
+		sb.WriteString(encodeVLQ(m.source - prevSource))
+		sb.WriteString(encodeVLQ(m.line - prevLine))
+		sb.WriteString(encodeVLQ(0))
+		prevSource, prevLine = m.source, m.line
+	}
+	return sb.String()
+}
+
+// encodeVLQ encodes a value as a base64 VLQ, as used by source maps
+func encodeVLQ(value int) string {
+	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+
+	var sb strings.Builder
+	for {
+		digit := vlq & 31
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 32
+		}
+		sb.WriteByte(chars[digit])
+		if vlq == 0 {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// relativePath returns the slash separated path to target from dir
+func relativePath(dir, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash("/"+dir), filepath.FromSlash("/"+target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// isExternalURL reports whether a reference points outside the site
+func isExternalURL(ref string) bool {
+	lower := strings.ToLower(ref)
+	return strings.HasPrefix(lower, "http:") || strings.HasPrefix(lower, "https:") || strings.HasPrefix(lower, "//")
+}
+
+// insideRanges reports whether pos falls inside one of the [start, end) ranges
+func insideRanges(pos int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// submatch returns the text of submatch n, or "" if it didn't participate
+func submatch(text string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return text[m[2*n]:m[2*n+1]]
+}
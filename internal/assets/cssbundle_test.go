@@ -0,0 +1,192 @@
+package assets
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// setupCSSSite creates a site whose stylesheets import each other across
+// the site and theme static directories
+func setupCSSSite(t *testing.T, files map[string]string) *Pipeline {
+	t.Helper()
+
+	sitePath := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	p := NewPipeline(config.DefaultConfig())
+	p.Reset(sitePath)
+	return p
+}
+
+func TestBundleCSS(t *testing.T) {
+	p := setupCSSSite(t, map[string]string{
+		"themes/default/static/css/main.css": `@import url("https://fonts.example.com/font.css");
+@import "base.css";
+@import url(components/card.css) screen;
+/* @import "ignored.css"; */
+main { display: block; }
+`,
+		"themes/default/static/css/base.css": "body { margin: 0; }\n",
+		"static/css/base.css":                "body { margin: 1rem; }\n",
+		"themes/default/static/css/components/card.css": `@import "../base.css";
+.card { background: url("img/bg.png"); }
+.logo { background: url(/images/logo.png); }
+`,
+	})
+
+	r, err := p.BundleCSS("css/main.css", "css/bundle.css", false)
+	if err != nil {
+		t.Fatalf("BundleCSS failed: %v", err)
+	}
+
+	expected := `@import url("https://fonts.example.com/font.css");
+body { margin: 1rem; }
+@media screen {
+.card { background: url("components/img/bg.png"); }
+.logo { background: url(/images/logo.png); }
+}
+/* @import "ignored.css"; */
+main { display: block; }
+`
+	if r.Content() != expected {
+		t.Errorf("Unexpected bundle:\n%s\nwant:\n%s", r.Content(), expected)
+	}
+}
+
+func TestBundleCSSCircularImport(t *testing.T) {
+	p := setupCSSSite(t, map[string]string{
+		"static/css/a.css": `@import "b.css";`,
+		"static/css/b.css": `@import "a.css";`,
+	})
+
+	_, err := p.BundleCSS("css/a.css", "", false)
+	if err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("Expected a circular import error, got %v", err)
+	}
+}
+
+func TestBundleCSSMissingImport(t *testing.T) {
+	p := setupCSSSite(t, map[string]string{
+		"static/css/a.css": `@import "missing.css";`,
+	})
+
+	_, err := p.BundleCSS("css/a.css", "", false)
+	if err == nil || !strings.Contains(err.Error(), "css/a.css") {
+		t.Errorf("Expected an error naming the importing file, got %v", err)
+	}
+}
+
+func TestBundleCSSSourceMap(t *testing.T) {
+	p := setupCSSSite(t, map[string]string{
+		"static/css/main.css": "@import \"base.css\";\nh1 { color: red; }\n",
+		"static/css/base.css": "body {\n  margin: 0;\n}\n",
+	})
+
+	r, err := p.BundleCSS("css/main.css", "css/bundle.css", true)
+	if err != nil {
+		t.Fatalf("BundleCSS failed: %v", err)
+	}
+
+	if !strings.HasSuffix(r.Content(), "/*# sourceMappingURL=bundle.css.map */\n") {
+		t.Errorf("Expected a sourceMappingURL comment, got:\n%s", r.Content())
+	}
+	if len(r.related) != 1 || r.related[0].Name() != "css/bundle.css.map" {
+		t.Fatalf("Expected a related source map resource")
+	}
+
+	var sourceMap struct {
+		Version  int      `json:"version"`
+		Sources  []string `json:"sources"`
+		Mappings string   `json:"mappings"`
+	}
+	if err := json.Unmarshal(r.related[0].content, &sourceMap); err != nil {
+		t.Fatalf("Invalid source map: %v", err)
+	}
+	if sourceMap.Version != 3 {
+		t.Errorf("Expected source map version 3, got %d", sourceMap.Version)
+	}
+	if strings.Join(sourceMap.Sources, ",") != "main.css,base.css" {
+		t.Errorf("Unexpected sources %v", sourceMap.Sources)
+	}
+
+	// base.css lines 0-2, then main.css line 1
+	if sourceMap.Mappings != "ACAA;AACA;AACA;ADDA;" {
+		t.Errorf("Unexpected mappings %q", sourceMap.Mappings)
+	}
+}
+
+func TestEncodeVLQ(t *testing.T) {
+	tests := map[int]string{0: "A", 1: "C", -1: "D", 15: "e", 16: "gB", -17: "jB"}
+	for value, expected := range tests {
+		if result := encodeVLQ(value); result != expected {
+			t.Errorf("encodeVLQ(%d) = %s, want %s", value, result, expected)
+		}
+	}
+}
+
+func TestBundleCSSResource(t *testing.T) {
+	p := setupCSSSite(t, map[string]string{
+		"static/css/main.css":  "@import \"base.css\";\nmain { display: block; }\n",
+		"static/css/extra.css": ".extra { color: red; }\n",
+		"static/css/base.css":  "body { margin: 0; }\n",
+		"static/js/app.js":     "console.log(1);\n",
+	})
+	ns := &Namespace{pipeline: p}
+
+	main, err := p.Get("css/main.css")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	extra, err := p.Get("css/extra.css")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// The bundle is made from the concatenated content, not a file
+	all, err := p.Concat("css/all.css", main, extra)
+	if err != nil {
+		t.Fatalf("Concat failed: %v", err)
+	}
+	r, err := ns.bundleCSS(all)
+	if err != nil {
+		t.Fatalf("bundleCSS failed: %v", err)
+	}
+	expected := "body { margin: 0; }\nmain { display: block; }\n.extra { color: red; }\n"
+	if r.Name() != "css/all.css" || r.Content() != expected {
+		t.Errorf("Unexpected bundle %s:\n%s\nwant:\n%s", r.Name(), r.Content(), expected)
+	}
+
+	// Processed resources are bundled as processed
+	minified, err := p.Minify(main)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	r, err = ns.bundleCSS(minified)
+	if err != nil {
+		t.Fatalf("bundleCSS failed: %v", err)
+	}
+	if !strings.Contains(r.Content(), "body { margin: 0; }") || !strings.Contains(r.Content(), "main{display:block}") {
+		t.Errorf("Expected the minified entry with its import inlined, got:\n%s", r.Content())
+	}
+
+	js, err := p.Get("js/app.js")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := ns.bundleCSS(js); err == nil || !strings.Contains(err.Error(), "not a stylesheet") {
+		t.Errorf("Expected an error for a script, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dikaio/scribe/internal/images"
+)
+
+// imageCacheDir is where processed images are kept between builds,
+// relative to the site root
+const imageCacheDir = "resources/_gen/images"
+
+// Width returns the width in pixels of an image resource
+func (r *Resource) Width() (int, error) {
+	if err := r.loadDimensions(); err != nil {
+		return 0, err
+	}
+	return r.width, nil
+}
+
+// Height returns the height in pixels of an image resource
+func (r *Resource) Height() (int, error) {
+	if err := r.loadDimensions(); err != nil {
+		return 0, err
+	}
+	return r.height, nil
+}
+
+// loadDimensions reads the image header once
+func (r *Resource) loadDimensions() error {
+	r.dimsOnce.Do(func() {
+		if r.width != 0 && r.height != 0 {
+			return
+		}
+		r.width, r.height, _, r.dimsErr = images.DecodeConfig(r.content)
+		if r.dimsErr != nil {
+			r.dimsErr = fmt.Errorf("%s: %w", r.name, r.dimsErr)
+		}
+	})
+	return r.dimsErr
+}
+
+// Resize scales the image to the given dimensions, e.g. "800x" keeps the
+// aspect ratio and "800x600" stretches to exactly that size
+func (r *Resource) Resize(spec string) (*Resource, error) {
+	return r.pipeline.processImage(r, images.OpResize, spec)
+}
+
+// Fit scales the image down to fit inside a box, e.g. "800x600"
+func (r *Resource) Fit(spec string) (*Resource, error) {
+	return r.pipeline.processImage(r, images.OpFit, spec)
+}
+
+// Fill scales and crops the image to exactly fill a box, e.g.
+// "400x300 center"
+func (r *Resource) Fill(spec string) (*Resource, error) {
+	return r.pipeline.processImage(r, images.OpFill, spec)
+}
+
+// processImage applies an image operation, reusing cached output from
+// previous builds when the source and parameters are unchanged
+func (p *Pipeline) processImage(r *Resource, op, specText string) (*Resource, error) {
+	if !images.IsImage(path.Ext(r.name)) {
+		return nil, fmt.Errorf("%s: %s is not a supported image (jpg, png or gif)", op, r.name)
+	}
+
+	spec, err := images.ParseSpec(specText)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", op, r.name, err)
+	}
+
+	// Apply site defaults
+	if spec.Quality == 0 {
+		spec.Quality = p.config.Imaging.Quality
+	}
+	if spec.Anchor == "" && op == images.OpFill {
+		spec.Anchor = images.Anchor(strings.ToLower(p.config.Imaging.Anchor))
+	}
+
+	return p.cached("image:"+op+":"+spec.String()+":"+r.key, func() (*Resource, error) {
+		format := spec.Format
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(path.Ext(r.name)), ".")
+		}
+
+		// The cache key covers the source content and every parameter
+		h := sha256.New()
+		h.Write(r.content)
+		fmt.Fprintf(h, "|%s|%s|%s", op, spec.String(), format)
+		hash := hex.EncodeToString(h.Sum(nil))[:16]
+
+		ext := path.Ext(r.name)
+		base := strings.TrimSuffix(path.Base(r.name), ext)
+		fileName := fmt.Sprintf("%s_%s_%dx%d_%s%s", base, op, spec.Width, spec.Height, hash, images.Extension(format))
+		name := path.Join(path.Dir(r.name), fileName)
+
+		cachePath := filepath.Join(p.sitePath, filepath.FromSlash(imageCacheDir), fileName)
+		if data, err := os.ReadFile(cachePath); err == nil {
+			return &Resource{pipeline: p, name: name, content: data}, nil
+		}
+
+		img, _, err := images.Decode(r.content)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op, r.name, err)
+		}
+		processed, err := images.Process(img, op, spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op, r.name, err)
+		}
+		data, err := images.Encode(processed, format, spec.Quality)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op, r.name, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(cachePath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write image cache: %w", err)
+		}
+
+		bounds := processed.Bounds()
+		return &Resource{pipeline: p, name: name, content: data, width: bounds.Dx(), height: bounds.Dy()}, nil
+	})
+}
+
+// PageResources gives templates access to the files stored next to a
+// page's Markdown file (a page bundle)
+type PageResources struct {
+	pipeline *Pipeline
+	dir      string
+	urlPath  string
+}
+
+// PageResources returns the resources bundled with the page whose source
+// file is pagePath and whose URL is pageURL
+func (p *Pipeline) PageResources(pagePath, pageURL string) *PageResources {
+	return &PageResources{
+		pipeline: p,
+		dir:      filepath.Dir(pagePath),
+		urlPath:  strings.Trim(pageURL, "/"),
+	}
+}
+
+// Get loads a file from the page bundle; it is published under the
+// page's URL. Paths leading out of the bundle are an error.
+func (pr *PageResources) Get(name string) (*Resource, error) {
+	if rel := path.Clean(filepath.ToSlash(name)); rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, fmt.Errorf("%w: %q in %s", ErrOutsideBundle, name, pr.dir)
+	}
+	name = cleanName(name)
+	srcPath := filepath.Join(pr.dir, filepath.FromSlash(name))
+
+	return pr.pipeline.cached("page:"+pr.urlPath+":"+srcPath, func() (*Resource, error) {
+		data, err := os.ReadFile(srcPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w: %q in %s", ErrNotFound, name, pr.dir)
+			}
+			return nil, err
+		}
+		return &Resource{pipeline: pr.pipeline, name: path.Join(pr.urlPath, name), content: data, sourcePath: srcPath}, nil
+	})
+}
@@ -0,0 +1,135 @@
+package assets
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// writeTestPNG writes a solid PNG of the given size
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.Set(0, 0, color.Black)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+}
+
+func TestImageProcessing(t *testing.T) {
+	sitePath := t.TempDir()
+	writeTestPNG(t, filepath.Join(sitePath, "assets", "images", "photo.png"), 160, 80)
+
+	p := NewPipeline(config.DefaultConfig())
+	p.Reset(sitePath)
+
+	src, err := p.Get("images/photo.png")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if w, _ := src.Width(); w != 160 {
+		t.Errorf("Expected width 160, got %d", w)
+	}
+
+	resized, err := src.Resize("80x")
+	if err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	w, _ := resized.Width()
+	h, _ := resized.Height()
+	if w != 80 || h != 40 {
+		t.Errorf("Expected 80x40, got %dx%d", w, h)
+	}
+	if !strings.HasPrefix(resized.Name(), "images/photo_resize_80x0_") || !strings.HasSuffix(resized.Name(), ".png") {
+		t.Errorf("Unexpected name %s", resized.Name())
+	}
+
+	filled, err := src.Fill("30x30 left jpg")
+	if err != nil {
+		t.Fatalf("Fill failed: %v", err)
+	}
+	if filled.MediaType() != "image/jpeg" {
+		t.Errorf("Expected a JPEG, got %s", filled.MediaType())
+	}
+
+	// Processed images are cached on disk
+	cachePath := filepath.Join(sitePath, filepath.FromSlash(imageCacheDir), filepath.Base(resized.Name()))
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("Expected cached image at %s", cachePath)
+	}
+
+	// A new pipeline reuses the cache
+	p.Reset(sitePath)
+	src, _ = p.Get("images/photo.png")
+	again, err := src.Resize("80x")
+	if err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	if again.Name() != resized.Name() {
+		t.Errorf("Expected the same name, got %s and %s", again.Name(), resized.Name())
+	}
+	if h, _ := again.Height(); h != 40 {
+		t.Errorf("Expected cached height 40, got %d", h)
+	}
+
+	if _, err := src.Fit("bad"); err == nil {
+		t.Errorf("Expected an error for an invalid spec")
+	}
+}
+
+func TestPageResources(t *testing.T) {
+	sitePath := t.TempDir()
+	pagePath := filepath.Join(sitePath, "content", "posts", "trip", "index.md")
+	writeTestPNG(t, filepath.Join(filepath.Dir(pagePath), "cover.png"), 40, 20)
+
+	p := NewPipeline(config.DefaultConfig())
+	p.Reset(sitePath)
+
+	r, err := p.PageResources(pagePath, "posts/trip/").Get("cover.png")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if r.Name() != "posts/trip/cover.png" {
+		t.Errorf("Expected posts/trip/cover.png, got %s", r.Name())
+	}
+
+	thumb, err := r.Fit("10x10")
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if !strings.HasPrefix(thumb.Name(), "posts/trip/cover_fit_10x10_") {
+		t.Errorf("Unexpected name %s", thumb.Name())
+	}
+
+	if _, err := p.PageResources(pagePath, "posts/trip/").Get("missing.png"); err == nil {
+		t.Errorf("Expected an error for a missing resource")
+	}
+
+	// Paths out of the bundle don't resolve to files inside it
+	writeTestPNG(t, filepath.Join(filepath.Dir(pagePath), "shared", "cover.png"), 40, 20)
+	for _, name := range []string{"../shared/cover.png", "sub/../../cover.png"} {
+		if _, err := p.PageResources(pagePath, "posts/trip/").Get(name); !errors.Is(err, ErrOutsideBundle) {
+			t.Errorf("%s: Expected ErrOutsideBundle, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Package assets implements the template asset pipeline: resources are
+// looked up in the site and theme, transformed (minified, fingerprinted,
+// concatenated) and published to the output directory on first use.
+package assets
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/minify"
+)
+
+// ErrNotFound is returned when a resource doesn't exist
+var ErrNotFound = errors.New("resource not found")
+
+// ErrOutsideBundle is returned for a page resource path leading out of the
+// page bundle, such as "../shared/a.png"
+var ErrOutsideBundle = errors.New("resource is outside the page bundle")
+
+// Pipeline resolves and transforms resources for templates
+type Pipeline struct {
+	config     config.Config
+	sitePath   string
+	outputPath string
+
+	mu        sync.Mutex
+	cache     map[string]*Resource
+	published map[string]*Resource
+}
+
+// NewPipeline creates a new asset pipeline
+func NewPipeline(cfg config.Config) *Pipeline {
+	return &Pipeline{
+		config:    cfg,
+		cache:     make(map[string]*Resource),
+		published: make(map[string]*Resource),
+	}
+}
+
+// Reset prepares the pipeline for a new build of the site at sitePath,
+// dropping resources cached by a previous build
+func (p *Pipeline) Reset(sitePath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sitePath = sitePath
+	p.outputPath = filepath.Join(sitePath, p.config.OutputDir)
+	p.cache = make(map[string]*Resource)
+	p.published = make(map[string]*Resource)
+}
+
+// sourceDirs returns the directories searched by Get, in priority order.
+// Site directories override theme directories, as with static files.
+func (p *Pipeline) sourceDirs() []string {
+	themePath := filepath.Join(p.sitePath, "themes", p.config.Theme)
+	return []string{
+		filepath.Join(p.sitePath, "assets"),
+		filepath.Join(themePath, "assets"),
+		filepath.Join(p.sitePath, p.config.StaticDir),
+		filepath.Join(themePath, "static"),
+	}
+}
+
+// cached returns the resource stored under key, creating it with build
+// on first use. Resources are shared across pages within a build.
+func (p *Pipeline) cached(key string, build func() (*Resource, error)) (*Resource, error) {
+	p.mu.Lock()
+	if r, ok := p.cache[key]; ok {
+		p.mu.Unlock()
+		return r, nil
+	}
+	p.mu.Unlock()
+
+	r, err := build()
+	if err != nil {
+		return nil, err
+	}
+	r.key = key
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.cache[key]; ok {
+		return existing, nil
+	}
+	p.cache[key] = r
+	return r, nil
+}
+
+// Get loads a resource by its path relative to the assets or static
+// directories, e.g. "css/style.css"
+func (p *Pipeline) Get(name string) (*Resource, error) {
+	name = cleanName(name)
+	return p.cached("get:"+name, func() (*Resource, error) {
+		for _, dir := range p.sourceDirs() {
+			srcPath := filepath.Join(dir, filepath.FromSlash(name))
+			data, err := os.ReadFile(srcPath)
+			if err == nil {
+				return &Resource{pipeline: p, name: name, content: data, sourcePath: srcPath}, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("%w: %q in assets or static directories", ErrNotFound, name)
+	})
+}
+
+// Concat joins resources of the same type into a single bundle published
+// at target
+func (p *Pipeline) Concat(target string, resources ...*Resource) (*Resource, error) {
+	target = cleanName(target)
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("concat %q: no resources given", target)
+	}
+
+	keys := make([]string, len(resources))
+	for i, r := range resources {
+		if r == nil {
+			return nil, fmt.Errorf("concat %q: resource %d is nil", target, i)
+		}
+		if path.Ext(r.name) != path.Ext(target) {
+			return nil, fmt.Errorf("concat %q: %q has a different file type", target, r.name)
+		}
+		keys[i] = r.key
+	}
+
+	return p.cached("concat:"+target+":"+strings.Join(keys, ","), func() (*Resource, error) {
+		var sb strings.Builder
+		for _, r := range resources {
+			sb.WriteString(strings.TrimRight(string(r.content), "\n"))
+			sb.WriteString("\n")
+		}
+		return &Resource{pipeline: p, name: target, content: []byte(sb.String())}, nil
+	})
+}
+
+// Minify returns a minified copy of a resource. Resources without a
+// supported minifier are returned unchanged.
+func (p *Pipeline) Minify(r *Resource) (*Resource, error) {
+	minifier := minify.ForExtension(r.name)
+	if minifier == nil {
+		return r, nil
+	}
+	return p.cached("minify:"+r.key, func() (*Resource, error) {
+		return &Resource{pipeline: p, name: r.name, content: minifier(r.content)}, nil
+	})
+}
+
+// Fingerprint returns a copy of a resource with a content hash in its
+// file name and a Subresource Integrity value in .Data.Integrity.
+// The hash algorithm is sha256 (default), sha384 or sha512.
+func (p *Pipeline) Fingerprint(algorithm string, r *Resource) (*Resource, error) {
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("fingerprint: unsupported algorithm %q", algorithm)
+	}
+
+	return p.cached("fingerprint:"+algorithm+":"+r.key, func() (*Resource, error) {
+		h.Write(r.content)
+		sum := h.Sum(nil)
+
+		ext := path.Ext(r.name)
+		name := fmt.Sprintf("%s.%s%s", strings.TrimSuffix(r.name, ext), hex.EncodeToString(sum)[:8], ext)
+
+		fingerprinted := &Resource{pipeline: p, name: name, content: r.content, related: r.related}
+		fingerprinted.Data.Integrity = algorithm + "-" + base64.StdEncoding.EncodeToString(sum)
+		return fingerprinted, nil
+	})
+}
+
+// publish writes a resource and its related resources to the output
+// directory once per build
+func (p *Pipeline) publish(r *Resource) error {
+	for _, related := range r.related {
+		if err := p.publish(related); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.published[r.name]; ok {
+		if existing != r && string(existing.content) != string(r.content) {
+			return fmt.Errorf("resource %s is published twice with different content; fingerprint one of them", r.name)
+		}
+		return nil
+	}
+
+	outPath := filepath.Join(p.outputPath, filepath.FromSlash(r.name))
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outPath, r.content, 0644); err != nil {
+		return fmt.Errorf("failed to publish resource %s: %w", r.name, err)
+	}
+
+	p.published[r.name] = r
+	return nil
+}
+
+// FuncMap returns the template functions backed by this pipeline
+func (p *Pipeline) FuncMap() template.FuncMap {
+	ns := &Namespace{pipeline: p}
+	return template.FuncMap{
+		"resources": func() *Namespace { return ns },
+		"minify":    p.Minify,
+		"fingerprint": func(args ...interface{}) (*Resource, error) {
+			return ns.Fingerprint(args...)
+		},
+		"bundleCSS": func(args ...interface{}) (*Resource, error) {
+			return ns.bundleCSS(args...)
+		},
+	}
+}
+
+// Namespace exposes the pipeline as the "resources" template namespace,
+// e.g. {{ $css := resources.Get "css/style.css" | minify | fingerprint }}
+type Namespace struct {
+	pipeline *Pipeline
+}
+
+// Get loads a resource from the assets or static directories
+func (ns *Namespace) Get(name string) (*Resource, error) {
+	return ns.pipeline.Get(name)
+}
+
+// Concat bundles resources into a single file published at target
+func (ns *Namespace) Concat(target string, resources ...*Resource) (*Resource, error) {
+	return ns.pipeline.Concat(target, resources...)
+}
+
+// BundleCSS bundles the @import chain of entry into a single stylesheet
+func (ns *Namespace) BundleCSS(entry string) (*Resource, error) {
+	return ns.pipeline.BundleCSS(entry, "", false)
+}
+
+// bundleCSS is the pipe form of BundleCSS: an optional "sourcemap" option
+// followed by the entry resource, e.g. resources.Get "css/main.css" | bundleCSS.
+// The resource's content is bundled, so it can come from Concat or Minify.
+func (ns *Namespace) bundleCSS(args ...interface{}) (*Resource, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("bundleCSS: expected a resource")
+	}
+
+	r, ok := args[len(args)-1].(*Resource)
+	if !ok || r == nil {
+		return nil, fmt.Errorf("bundleCSS: argument is not a resource")
+	}
+
+	sourceMap := false
+	for _, arg := range args[:len(args)-1] {
+		option, _ := arg.(string)
+		switch strings.ToLower(option) {
+		case "sourcemap":
+			sourceMap = true
+		default:
+			return nil, fmt.Errorf("bundleCSS: unknown option %v", arg)
+		}
+	}
+
+	return ns.pipeline.BundleCSSResource(r, sourceMap)
+}
+
+// Minify returns a minified copy of a resource
+func (ns *Namespace) Minify(r *Resource) (*Resource, error) {
+	return ns.pipeline.Minify(r)
+}
+
+// Fingerprint accepts an optional algorithm followed by the resource, so
+// it works both as "fingerprint" and "fingerprint \"sha384\"" in a pipe
+func (ns *Namespace) Fingerprint(args ...interface{}) (*Resource, error) {
+	var algorithm string
+	var r *Resource
+
+	switch len(args) {
+	case 1:
+		r, _ = args[0].(*Resource)
+	case 2:
+		algorithm, _ = args[0].(string)
+		r, _ = args[1].(*Resource)
+	default:
+		return nil, fmt.Errorf("fingerprint: expected [algorithm] resource, got %d arguments", len(args))
+	}
+	if r == nil {
+		return nil, fmt.Errorf("fingerprint: argument is not a resource")
+	}
+
+	return ns.pipeline.Fingerprint(algorithm, r)
+}
+
+// cleanName normalizes a resource path to a slash separated relative path
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
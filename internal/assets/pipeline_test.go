@@ -0,0 +1,146 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// setupPipeline creates a site with theme and site assets
+func setupPipeline(t *testing.T) (string, *Pipeline) {
+	t.Helper()
+
+	sitePath := t.TempDir()
+	files := map[string]string{
+		"themes/default/static/css/style.css": "body {\n  color: red;\n}\n",
+		"themes/default/static/css/extra.css": "p { margin: 0; }\n",
+		"assets/css/style.css":                "body {\n  color: blue;\n}\n",
+		"static/js/app.js":                    "// app\nconsole.log(1)\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://example.com/"
+	p := NewPipeline(cfg)
+	p.Reset(sitePath)
+	return sitePath, p
+}
+
+func TestGetPrefersSiteAssets(t *testing.T) {
+	_, p := setupPipeline(t)
+
+	r, err := p.Get("css/style.css")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Contains(r.content, []byte("blue")) {
+		t.Errorf("Expected site assets to override theme static, got %q", r.content)
+	}
+
+	if _, err := p.Get("css/missing.css"); err == nil {
+		t.Error("Expected an error for a missing resource")
+	}
+}
+
+func TestMinifyAndFingerprint(t *testing.T) {
+	sitePath, p := setupPipeline(t)
+
+	r, err := p.Get("css/style.css")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	minified, err := p.Minify(r)
+	if err != nil {
+		t.Fatalf("Minify failed: %v", err)
+	}
+	fingerprinted, err := p.Fingerprint("", minified)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	if !regexp.MustCompile(`^css/style\.[0-9a-f]{8}\.css$`).MatchString(fingerprinted.Name()) {
+		t.Errorf("Unexpected fingerprinted name %s", fingerprinted.Name())
+	}
+
+	sum := sha256.Sum256([]byte("body{color:blue}"))
+	expectedIntegrity := "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+	if fingerprinted.Data.Integrity != expectedIntegrity {
+		t.Errorf("Expected integrity %s, got %s", expectedIntegrity, fingerprinted.Data.Integrity)
+	}
+
+	// Publishing writes the file under its fingerprinted name
+	rel, err := fingerprinted.RelPermalink()
+	if err != nil {
+		t.Fatalf("RelPermalink failed: %v", err)
+	}
+	if rel != "/"+fingerprinted.Name() {
+		t.Errorf("Expected RelPermalink /%s, got %s", fingerprinted.Name(), rel)
+	}
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", filepath.FromSlash(fingerprinted.Name())))
+	if err != nil {
+		t.Fatalf("Expected published file: %v", err)
+	}
+	if string(data) != "body{color:blue}" {
+		t.Errorf("Unexpected published content %q", data)
+	}
+
+	// The same transformation chain is cached
+	again, _ := p.Minify(r)
+	if again != minified {
+		t.Error("Expected repeated transformations to be cached")
+	}
+}
+
+func TestConcat(t *testing.T) {
+	_, p := setupPipeline(t)
+
+	style, _ := p.Get("css/style.css")
+	extra, _ := p.Get("css/extra.css")
+
+	bundle, err := p.Concat("css/bundle.css", style, extra)
+	if err != nil {
+		t.Fatalf("Concat failed: %v", err)
+	}
+	expected := "body {\n  color: blue;\n}\np { margin: 0; }\n"
+	if bundle.Content() != expected {
+		t.Errorf("Expected bundle %q, got %q", expected, bundle.Content())
+	}
+
+	script, _ := p.Get("js/app.js")
+	if _, err := p.Concat("css/bundle.css", style, script); err == nil {
+		t.Error("Expected an error when concatenating different file types")
+	}
+}
+
+func TestTemplateFunctions(t *testing.T) {
+	_, p := setupPipeline(t)
+
+	tmpl := template.Must(template.New("test").Funcs(p.FuncMap()).Parse(
+		`{{ $css := resources.Get "css/style.css" | minify | fingerprint "sha384" }}` +
+			`<link rel="stylesheet" href="{{ $css.RelPermalink }}" integrity="{{ $css.Data.Integrity }}">`))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		t.Fatalf("Template execution failed: %v", err)
+	}
+
+	pattern := `^<link rel="stylesheet" href="/css/style\.[0-9a-f]{8}\.css" integrity="sha384-[A-Za-z0-9+/=]+">$`
+	if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+		t.Errorf("Unexpected template output: %s", buf.String())
+	}
+}
@@ -0,0 +1,73 @@
+package assets
+
+import (
+	"mime"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Resource is a file produced by the asset pipeline
+type Resource struct {
+	pipeline   *Pipeline
+	key        string
+	name       string
+	content    []byte
+	sourcePath string
+	// related resources (e.g. source maps) are published alongside
+	related []*Resource
+
+	// Image dimensions, read lazily from the image header
+	width, height int
+	dimsOnce      sync.Once
+	dimsErr       error
+
+	// Data holds metadata produced by transformations
+	Data ResourceData
+}
+
+// ResourceData holds metadata about a resource
+type ResourceData struct {
+	// Integrity is a Subresource Integrity value set by fingerprint
+	Integrity string
+}
+
+// Name returns the resource path relative to the output directory
+func (r *Resource) Name() string {
+	return r.name
+}
+
+// Content returns the resource content
+func (r *Resource) Content() string {
+	return string(r.content)
+}
+
+// MediaType returns the MIME type derived from the file extension
+func (r *Resource) MediaType() string {
+	mediaType := mime.TypeByExtension(path.Ext(r.name))
+	if idx := strings.Index(mediaType, ";"); idx >= 0 {
+		mediaType = mediaType[:idx]
+	}
+	return mediaType
+}
+
+// Publish writes the resource to the output directory
+func (r *Resource) Publish() error {
+	return r.pipeline.publish(r)
+}
+
+// RelPermalink publishes the resource and returns its root-relative URL
+func (r *Resource) RelPermalink() (string, error) {
+	if err := r.pipeline.publish(r); err != nil {
+		return "", err
+	}
+	return r.pipeline.config.RelURL(r.name), nil
+}
+
+// Permalink publishes the resource and returns its absolute URL
+func (r *Resource) Permalink() (string, error) {
+	if err := r.pipeline.publish(r); err != nil {
+		return "", err
+	}
+	return r.pipeline.config.AbsURL(r.name), nil
+}
@@ -0,0 +1,174 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// redirectFormats maps the supported redirects config values to the file
+// they produce and the format of each line
+var redirectFormats = map[string]struct {
+	file string
+	line string
+}{
+	// Netlify and Cloudflare Pages
+	"netlify": {file: "_redirects", line: "%s %s 301\n"},
+	// Apache mod_alias
+	"apache": {file: ".htaccess", line: "Redirect 301 %s %s\n"},
+	// nginx, for use with: map $uri $redirect { include redirects.map; }
+	"nginx": {file: "redirects.map", line: "%s %s;\n"},
+}
+
+// alias is an old URL redirecting to a page
+type alias struct {
+	// from is the site path of the alias, and url the same path with the
+	// base path, as requested from the server
+	from   string
+	url    string
+	target string
+	source string
+}
+
+// aliasOutputPath returns the output file for a URL path, relative to the
+// output directory. Paths ending in .html are used as is.
+func aliasOutputPath(urlPath string) string {
+	clean := strings.Trim(path.Clean("/"+urlPath), "/")
+	if strings.HasSuffix(clean, ".html") || strings.HasSuffix(clean, ".htm") {
+		return clean
+	}
+	return path.Join(clean, "index.html")
+}
+
+// collectAliases validates the aliases of all pages. An alias may not
+// replace a generated page or be claimed by two pages.
+func (b *Builder) collectAliases() ([]alias, error) {
+	// Files generated for pages, and who generated them
+	occupied := map[string]string{
+		"index.html": "the home page",
+		"404.html":   "the 404 page",
+	}
+	for _, page := range b.pages {
+		occupied[aliasOutputPath(page.URL)] = page.Path
+	}
+	for tag := range b.tags {
+		occupied[aliasOutputPath("tags/"+tag)] = fmt.Sprintf("the tag page for %q", tag)
+	}
+
+	basePath := b.config.BasePath()
+
+	var aliases []alias
+	claimed := make(map[string]string)
+	for _, page := range b.pages {
+		for _, from := range page.Aliases {
+			// Aliases are site paths; the base path is optional
+			from = "/" + strings.TrimPrefix(from, "/")
+			if basePath != "/" && strings.HasPrefix(from, basePath) {
+				from = "/" + strings.TrimPrefix(from, basePath)
+			}
+
+			file := aliasOutputPath(from)
+			if owner, ok := occupied[file]; ok {
+				return nil, fmt.Errorf("%s: alias %q conflicts with %s", page.Path, from, owner)
+			}
+			if owner, ok := claimed[file]; ok {
+				return nil, fmt.Errorf("%s: alias %q is also an alias of %s", page.Path, from, owner)
+			}
+			claimed[file] = page.Path
+
+			aliases = append(aliases, alias{
+				from:   from,
+				url:    b.config.RelURL(from),
+				target: page.RelPermalink,
+				source: page.Path,
+			})
+		}
+	}
+
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].from < aliases[j].from
+	})
+	return aliases, nil
+}
+
+// generateAliases writes redirect pages for page aliases, keeping the
+// aliases for the server redirect files
+func (b *Builder) generateAliases(outputPath string) error {
+	for _, format := range b.config.Redirects {
+		if _, ok := redirectFormats[format]; !ok {
+			return fmt.Errorf("unknown redirects format %q (use netlify, apache or nginx)", format)
+		}
+	}
+
+	aliases, err := b.collectAliases()
+	if err != nil {
+		return err
+	}
+	b.aliases = aliases
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	permalinks := make(map[string]string, len(b.pages))
+	for _, page := range b.pages {
+		permalinks[page.Path] = page.Permalink
+	}
+
+	for _, a := range aliases {
+		outputFile := filepath.Join(outputPath, filepath.FromSlash(aliasOutputPath(a.from)))
+		if err := b.renderer.RenderAlias(a.target, permalinks[a.source], outputFile); err != nil {
+			return fmt.Errorf("error rendering alias %s: %w", a.from, err)
+		}
+	}
+	return nil
+}
+
+// generateRedirects writes the server redirect files selected by the
+// redirects config option to outputPath, the root the server reads them
+// from, with the aliases of the sites published below it
+func (b *Builder) generateRedirects(sitePath, outputPath string, sites []*Builder) error {
+	var aliases []alias
+	for _, site := range sites {
+		if site.config.Root().BaseURL == b.config.BaseURL {
+			aliases = append(aliases, site.aliases...)
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].url < aliases[j].url
+	})
+
+	for _, format := range b.config.Redirects {
+		rf := redirectFormats[format]
+
+		// Keep hand-written rules from a static file of the same name
+		var sb strings.Builder
+		for _, dir := range []string{
+			filepath.Join(sitePath, b.config.StaticDir),
+			filepath.Join(sitePath, "themes", b.config.Theme, "static"),
+		} {
+			if data, err := os.ReadFile(filepath.Join(dir, rf.file)); err == nil {
+				sb.Write(data)
+				if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+					sb.WriteString("\n")
+				}
+				break
+			}
+		}
+
+		for _, a := range aliases {
+			fmt.Fprintf(&sb, rf.line, a.url, a.target)
+		}
+
+		if err := os.WriteFile(filepath.Join(outputPath, rf.file), []byte(sb.String()), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
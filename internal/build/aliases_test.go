@@ -0,0 +1,153 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+func TestBuildAliases(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Redirects = []string{"netlify", "apache", "nginx"}
+
+	page := "---\ntitle: Moved\naliases:\n  - /old-post/\n  - archive/moved.html\n---\nMoved."
+	if err := os.WriteFile(filepath.Join(sitePath, "content", "posts", "moved.md"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(sitePath, "static"), 0755); err != nil {
+		t.Fatalf("Failed to create static directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sitePath, "static", "_redirects"), []byte("/feed /index.xml 301"), 0644); err != nil {
+		t.Fatalf("Failed to write _redirects: %v", err)
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+
+	// Rebuilding must not duplicate redirect rules
+	for i := 0; i < 2; i++ {
+		if err := builder.Build(sitePath); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "old-post", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected alias page to be written: %v", err)
+	}
+	for _, s := range []string{
+		`<link rel="canonical" href="https://example.com/posts/moved/">`,
+		`<meta http-equiv="refresh" content="0; url=/posts/moved/">`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("Expected alias page to contain %q, got:\n%s", s, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "archive", "moved.html")); err != nil {
+		t.Errorf("Expected .html alias to be written as is: %v", err)
+	}
+
+	expected := map[string]string{
+		"_redirects":    "/feed /index.xml 301\n/archive/moved.html /posts/moved/ 301\n/old-post/ /posts/moved/ 301\n",
+		".htaccess":     "Redirect 301 /archive/moved.html /posts/moved/\nRedirect 301 /old-post/ /posts/moved/\n",
+		"redirects.map": "/archive/moved.html /posts/moved/;\n/old-post/ /posts/moved/;\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(sitePath, "public", name))
+		if err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Unexpected %s:\n%s\nwant:\n%s", name, data, content)
+		}
+	}
+}
+
+func TestBuildAliasConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases string
+		message string
+	}{
+		{name: "page", aliases: "[/about/]", message: `alias "/about/" conflicts with`},
+		{name: "tag page", aliases: "[/tags/intro]", message: `conflicts with the tag page for "intro"`},
+		{name: "home page", aliases: "[/]", message: "conflicts with the home page"},
+		{name: "other alias", aliases: "[/x/, /x]", message: "is also an alias of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sitePath, cfg := setupTestSite(t)
+			page := "---\ntitle: Conflict\naliases: " + tt.aliases + "\n---\nConflict."
+			if err := os.WriteFile(filepath.Join(sitePath, "content", "conflict.md"), []byte(page), 0644); err != nil {
+				t.Fatalf("Failed to write page: %v", err)
+			}
+
+			builder := NewBuilder(cfg)
+			builder.SetQuiet(true)
+			err := builder.Build(sitePath)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestBuildAliasesMultilingual(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Redirects = []string{"netlify"}
+	cfg.Languages = map[string]config.Language{
+		"en": {Weight: 1},
+		"de": {Weight: 2},
+		"fr": {Weight: 3, BaseURL: "https://example.fr/", ContentDir: "content/fr"},
+	}
+
+	files := map[string]string{
+		"content/posts/moved.md":      "---\ntitle: Moved\naliases: [/old-post/]\n---\nMoved.",
+		"content/posts/hello.de.md":   "---\ntitle: Hallo\naliases: [/alt/]\n---\nHallo.",
+		"content/fr/posts/bonjour.md": "---\ntitle: Bonjour\naliases: [/ancien/]\n---\nBonjour.",
+		"static/_redirects":           "/feed /index.xml 301\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Languages below the site root share its redirect file, while a
+	// language at its own baseURL has its own
+	expected := map[string]string{
+		"_redirects":    "/feed /index.xml 301\n/de/alt/ /de/posts/hello/ 301\n/old-post/ /posts/moved/ 301\n",
+		"fr/_redirects": "/feed /index.xml 301\n/ancien/ /posts/bonjour/ 301\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(sitePath, "public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Unexpected %s:\n%s\nwant:\n%s", name, data, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "de", "_redirects")); err == nil {
+		t.Error("Expected no redirect file below the site root")
+	}
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "de", "alt", "index.html")); err != nil {
+		t.Errorf("Expected the German alias page to be written: %v", err)
+	}
+}
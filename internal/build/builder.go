@@ -8,10 +8,17 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dikaio/scribe/internal/config"
 	"github.com/dikaio/scribe/internal/content"
+	"github.com/dikaio/scribe/internal/data"
+	"github.com/dikaio/scribe/internal/i18n"
+	"github.com/dikaio/scribe/internal/menu"
+	"github.com/dikaio/scribe/internal/minify"
 	"github.com/dikaio/scribe/internal/render"
+	"github.com/dikaio/scribe/internal/rss"
+	"github.com/dikaio/scribe/internal/search"
 	"github.com/dikaio/scribe/internal/sitemap"
 )
 
@@ -21,12 +28,54 @@ type Builder struct {
 	renderer *render.Renderer
 	pages    []content.Page
 	tags     map[string][]content.Page
+	index    *content.Index
 	quiet    bool
 	devMode  bool
+	minify   bool
+	// changedFiles lists the files that triggered a rebuild, exposed to hooks
+	changedFiles []string
+	// sitemapRoot is the sitemap file crawlers are pointed at
+	sitemapRoot string
+	// aliases are the page aliases of the last build, for the server
+	// redirect files
+	aliases []alias
+	// sites are the builders of every language of a multilingual site in
+	// order, including this one for the default language; just this one
+	// otherwise
+	sites []*Builder
+	// fallback is the builder of the default language, whose pages links
+	// from another language fall back to
+	fallback *Builder
 }
 
 // NewBuilder creates a new site builder
 func NewBuilder(cfg config.Config) *Builder {
+	siteConfig := cfg
+	if cfg.IsMultilingual() {
+		cfg = siteConfig.ForLanguage(siteConfig.Language)
+	}
+
+	b := newLanguageBuilder(cfg)
+
+	// The other languages of a multilingual site are built alongside
+	b.sites = []*Builder{b}
+	if cfg.IsMultilingual() {
+		b.sites = nil
+		for _, lang := range siteConfig.LanguageList() {
+			if lang.Code == siteConfig.Language {
+				b.sites = append(b.sites, b)
+				continue
+			}
+			site := newLanguageBuilder(siteConfig.ForLanguage(lang.Code))
+			site.fallback = b
+			b.sites = append(b.sites, site)
+		}
+	}
+	return b
+}
+
+// newLanguageBuilder creates the builder of one language of a site
+func newLanguageBuilder(cfg config.Config) *Builder {
 	return &Builder{
 		config:   cfg,
 		renderer: render.NewRenderer(cfg),
@@ -34,12 +83,27 @@ func NewBuilder(cfg config.Config) *Builder {
 		tags:     make(map[string][]content.Page),
 		quiet:    false,
 		devMode:  false,
+		minify:   cfg.Minify,
 	}
 }
 
+// languages returns the builders of the site's other languages
+func (b *Builder) languages() []*Builder {
+	var languages []*Builder
+	for _, site := range b.sites {
+		if site != b {
+			languages = append(languages, site)
+		}
+	}
+	return languages
+}
+
 // SetQuiet sets the quiet mode for the builder
 func (b *Builder) SetQuiet(quiet bool) {
 	b.quiet = quiet
+	for _, site := range b.languages() {
+		site.SetQuiet(quiet)
+	}
 }
 
 // SetDevMode sets the development mode for the builder and renderer
@@ -47,31 +111,143 @@ func (b *Builder) SetQuiet(quiet bool) {
 func (b *Builder) SetDevMode(enabled bool) {
 	b.devMode = enabled
 	b.renderer.SetDevMode(enabled)
+	for _, site := range b.languages() {
+		site.SetDevMode(enabled)
+	}
+}
+
+// SetMinify enables or disables minification of HTML, CSS, JS and XML output
+func (b *Builder) SetMinify(enabled bool) {
+	b.minify = enabled
+	b.renderer.SetMinify(enabled)
+	for _, site := range b.languages() {
+		site.SetMinify(enabled)
+	}
+}
+
+// SetChangedFiles records the files that triggered the next build.
+// The list is passed to hook commands through SCRIBE_CHANGED_FILES.
+func (b *Builder) SetChangedFiles(files []string) {
+	b.changedFiles = files
 }
 
 // Build builds the site
 func (b *Builder) Build(sitePath string) error {
-	// Initialize renderer
-	if err := b.renderer.Init(sitePath); err != nil {
+	outputPath := filepath.Join(sitePath, b.config.OutputDir)
+
+	// Run pre-build hooks before anything reads the site sources,
+	// so generated assets (e.g. compiled CSS) are picked up
+	if err := b.runHooks(hookPreBuild, b.config.Hooks.PreBuild, sitePath, outputPath); err != nil {
+		return err
+	}
+
+	// Load data files, theme first so the site's files take precedence
+	siteData, err := data.Load(
+		filepath.Join(sitePath, "themes", b.config.Theme, "data"),
+		filepath.Join(sitePath, "data"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Load the i18n string tables the same way
+	translations, err := i18n.Load(
+		b.config.DefaultLanguage(),
+		filepath.Join(sitePath, "themes", b.config.Theme, "i18n"),
+		filepath.Join(sitePath, "i18n"),
+	)
+	if err != nil {
 		return err
 	}
 
-	// Load content
+	// Initialize the renderer of each language
+	languages := b.siteLanguages()
+	for _, site := range b.sites {
+		if err := site.renderer.Init(sitePath); err != nil {
+			return err
+		}
+		site.renderer.SetData(siteData)
+		site.renderer.SetTranslations(translations)
+		site.renderer.SetLanguages(languages)
+	}
+
+	// Load content, the default language first since links from the
+	// other languages fall back to its pages
 	if err := b.loadContent(sitePath); err != nil {
 		return err
 	}
+	for _, site := range b.languages() {
+		if err := site.loadContent(sitePath); err != nil {
+			return err
+		}
+	}
+	linkTranslations(b.sites)
+	for _, site := range b.sites {
+		site.linkPages()
+	}
 
 	// Create output directory
-	outputPath := filepath.Join(sitePath, b.config.OutputDir)
 	if err := os.MkdirAll(outputPath, 0755); err != nil {
 		return err
 	}
 
-	// Copy static files
-	if err := b.copyStaticFiles(sitePath, outputPath); err != nil {
+	for _, site := range b.sites {
+		siteOutputPath := filepath.Join(sitePath, site.config.OutputDir)
+
+		// Static files are served from the site root, so languages only
+		// need their own copy when they are published at their own baseURL
+		if site.config.Root().BaseURL == site.config.BaseURL {
+			// Copy static files
+			if err := site.copyStaticFiles(sitePath, siteOutputPath); err != nil {
+				return err
+			}
+
+			// Bundle stylesheets declared in the config
+			if err := site.generateCSSBundles(); err != nil {
+				return err
+			}
+		}
+
+		if err := site.generateContent(siteOutputPath); err != nil {
+			return err
+		}
+	}
+
+	// Servers read redirect files at the root they serve, so each is
+	// written once with the aliases of the languages published below it
+	for _, site := range b.sites {
+		if site.config.Root().BaseURL == site.config.BaseURL {
+			siteOutputPath := filepath.Join(sitePath, site.config.OutputDir)
+			if err := site.generateRedirects(sitePath, siteOutputPath, b.sites); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Generate sitemap
+	if err := b.generateSitemap(outputPath); err != nil {
 		return err
 	}
 
+	// Generate robots.txt, replacing any static copy
+	if b.config.EnableRobotsTXT {
+		sitemapURL := b.config.AbsURL(b.sitemapRoot)
+		if err := b.renderer.RenderRobots(sitePath, sitemapURL, filepath.Join(outputPath, "robots.txt")); err != nil {
+			return fmt.Errorf("error rendering robots.txt: %w", err)
+		}
+	}
+
+	// Run post-build hooks once the output tree is complete
+	if err := b.runHooks(hookPostBuild, b.config.Hooks.PostBuild, sitePath, outputPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// generateContent generates the pages of the site, or of one language of
+// a multilingual site, in outputPath
+func (b *Builder) generateContent(outputPath string) error {
 	// Generate pages in parallel
 	if err := b.generatePages(outputPath); err != nil {
 		return err
@@ -87,18 +263,48 @@ func (b *Builder) Build(sitePath string) error {
 		return err
 	}
 
-	// Generate sitemap
-	if err := b.generateSitemap(outputPath); err != nil {
+	// Generate the RSS feed of recent posts
+	if err := b.generateFeed(outputPath); err != nil {
 		return err
 	}
 
+	// Generate the page served for missing URLs
+	if err := b.generate404Page(outputPath); err != nil {
+		return err
+	}
+
+	// Generate redirect pages for page aliases
+	if err := b.generateAliases(outputPath); err != nil {
+		return err
+	}
+
+	// Generate the search index
+	if b.config.SearchIndex.Enable {
+		if err := b.generateSearchIndex(outputPath); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // loadContent loads all content files
 func (b *Builder) loadContent(sitePath string) error {
 	contentPath := filepath.Join(sitePath, b.config.ContentDir)
+
+	// Reset collections so repeated builds (e.g. in the dev server)
+	// don't accumulate duplicate pages
+	b.pages = []content.Page{}
+	b.tags = make(map[string][]content.Page)
 	
+	// The content directories of other languages may be inside this one
+	otherContentDirs := make(map[string]bool)
+	for code, lang := range b.config.Languages {
+		if code != b.config.Language && lang.ContentDir != "" {
+			otherContentDirs[filepath.Join(sitePath, lang.ContentDir)] = true
+		}
+	}
+
 	// First, collect all markdown files
 	var markdownFiles []string
 	err := filepath.Walk(contentPath, func(path string, info os.FileInfo, err error) error {
@@ -108,6 +314,9 @@ func (b *Builder) loadContent(sitePath string) error {
 
 		// Skip directories
 		if info.IsDir() {
+			if path != contentPath && otherContentDirs[path] {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -131,13 +340,28 @@ func (b *Builder) loadContent(sitePath string) error {
 		for job := range jobs {
 			filePath := job.(string)
 			
-			// Load page
-			page, err := content.LoadPage(filePath, b.config.BaseURL, b.config.TrailingSlash)
+			// Load page metadata; the body is rendered once all URLs are known
+			page, ok, err := b.parsePage(contentPath, filePath)
 			if err != nil {
 				errChan <- fmt.Errorf("error loading %s: %v", filePath, err)
 				continue
 			}
 
+			// Pages in another language are loaded by its builder
+			if !ok {
+				continue
+			}
+
+			// Apply the permalink pattern of the page's section
+			if pattern, ok := b.config.Permalinks[page.Section]; ok {
+				url, err := content.ExpandPermalink(pattern, page)
+				if err != nil {
+					errChan <- fmt.Errorf("error loading %s: %v", filePath, err)
+					continue
+				}
+				page.SetURL(url, b.config.BaseURL, b.config.TrailingSlash)
+			}
+
 			// Skip draft pages in production
 			if page.Draft {
 				// Skip silently
@@ -162,19 +386,226 @@ func (b *Builder) loadContent(sitePath string) error {
 		return errors[0]
 	}
 
+	var pages []content.Page
+	for _, result := range resultsInterface {
+		pages = append(pages, result.(content.Page))
+	}
+
+	// Pages without a lastmod in front matter take it from the git
+	// history, then from their date
+	var gitInfo map[string]time.Time
+	if b.config.EnableGitInfo {
+		if gitInfo, err = gitLastmod(contentPath); err != nil {
+			return err
+		}
+	}
+	for i := range pages {
+		if pages[i].Lastmod.IsZero() {
+			pages[i].Lastmod = gitInfo[pages[i].Path]
+		}
+		if pages[i].Lastmod.IsZero() {
+			pages[i].Lastmod = pages[i].Date
+		}
+	}
+
+	// Two pages can't be written to the same place
+	if err := checkURLCollisions(pages); err != nil {
+		return err
+	}
+
+	// Index pages by source file so links between them can be resolved.
+	// Links to pages that aren't translated resolve to the default
+	// language's.
+	indexed := pages
+	if b.fallback != nil {
+		indexed = append(append([]content.Page{}, pages...), b.fallback.pages...)
+	}
+	b.index = content.NewIndex(contentPath, indexed)
+	b.renderer.SetIndex(b.index)
+
+	// Render Markdown in parallel now that every page URL is known
+	renderWorker := func(workerID int, jobs <-chan interface{}, results chan<- interface{}, errChan chan<- error, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		for job := range jobs {
+			page := job.(content.Page)
+
+			if err := b.resolvePageImages(&page); err != nil {
+				errChan <- err
+				continue
+			}
+
+			hooks := b.index.Hooks(page.Path)
+			hooks.Image = b.imageRenderer(page)
+			if err := page.RenderHTML(hooks); err != nil {
+				errChan <- err
+				continue
+			}
+
+			results <- page
+		}
+	}
+
+	renderJobs := make([]interface{}, len(pages))
+	for i, page := range pages {
+		renderJobs[i] = page
+	}
+
+	resultsInterface, errors = parallelExecutor(renderJobs, renderWorker)
+	if len(errors) > 0 {
+		return errors[0]
+	}
+
 	// Process results
 	for _, result := range resultsInterface {
-		page := result.(content.Page)
-		
 		// Add page to collection
-		b.pages = append(b.pages, page)
+		b.pages = append(b.pages, result.(content.Page))
+	}
+	b.collectTags()
+
+	// Menus link to pages, so they are built once every URL is known
+	menus, err := menu.Build(b.config, b.pages, b.index)
+	if err != nil {
+		return err
+	}
+	b.renderer.SetMenus(menus)
+	
+	return nil
+}
+
+// parsePage loads the metadata of a content file in contentPath. On a
+// multilingual site it reports false for pages in another language: files
+// named like post.de.md, or setting lang in front matter, in a content
+// directory shared with the default language.
+func (b *Builder) parsePage(contentPath, filePath string) (content.Page, bool, error) {
+	// The URL and section come from the path in the content directory,
+	// wherever the site and its content directory are
+	rel, err := filepath.Rel(contentPath, filePath)
+	if err != nil {
+		return content.Page{}, false, err
+	}
+
+	if !b.config.IsMultilingual() {
+		page, err := content.ParsePageAt(filePath, rel, b.config.BaseURL, b.config.TrailingSlash)
+		return page, true, err
+	}
+
+	// A language suffix is left out of the URL
+	lang := ""
+	ext := filepath.Ext(rel)
+	if suffix := filepath.Ext(strings.TrimSuffix(rel, ext)); suffix != "" {
+		if _, ok := b.config.Languages[suffix[1:]]; ok {
+			lang = suffix[1:]
+			rel = strings.TrimSuffix(rel, suffix+ext) + ext
+		}
+	}
+
+	page, err := content.ParsePageAt(filePath, rel, b.config.BaseURL, b.config.TrailingSlash)
+	if err != nil {
+		return page, false, err
+	}
+
+	if _, ok := b.config.Languages[page.Lang]; lang == "" && ok {
+		lang = page.Lang
+	}
+	if lang == "" {
+		// Other files belong to the language of the content directory
+		lang = b.config.DefaultLanguage()
+		if b.config.Languages[b.config.Language].ContentDir != "" {
+			lang = b.config.Language
+		}
+	}
+	page.Lang = lang
+
+	// Translations in a shared content directory have the same path
+	if page.TranslationKey == "" {
+		page.TranslationKey = filepath.ToSlash(strings.TrimSuffix(rel, ext))
+	}
+
+	return page, lang == b.config.Language, nil
+}
 
-		// Add page to tags
+// collectTags groups the pages by tag
+func (b *Builder) collectTags() {
+	b.tags = make(map[string][]content.Page)
+	for _, page := range b.pages {
 		for _, tag := range page.Tags {
 			b.tags[tag] = append(b.tags[tag], page)
 		}
 	}
-	
+}
+
+// linkTranslations sets the Translations of the pages of sites that share
+// a TranslationKey
+func linkTranslations(sites []*Builder) {
+	groups := make(map[string][]content.Page)
+	for _, site := range sites {
+		for _, page := range site.pages {
+			if page.TranslationKey != "" {
+				groups[page.TranslationKey] = append(groups[page.TranslationKey], page)
+			}
+		}
+	}
+
+	for _, site := range sites {
+		for i, page := range site.pages {
+			for _, other := range groups[page.TranslationKey] {
+				if other.Path != page.Path {
+					site.pages[i].Translations = append(site.pages[i].Translations, other)
+				}
+			}
+		}
+	}
+}
+
+// linkPages links each page to the pages before and after it and to its
+// related pages, once every page is loaded
+func (b *Builder) linkPages() {
+	content.LinkPages(b.pages, b.config.Related)
+	b.renderer.SetPages(b.pages)
+
+	// Tag pages list the pages with their links
+	b.collectTags()
+}
+
+// siteLanguages returns the languages of a multilingual site for
+// .Site.Languages, or nil
+func (b *Builder) siteLanguages() []render.Language {
+	if !b.config.IsMultilingual() {
+		return nil
+	}
+
+	var languages []render.Language
+	for _, site := range b.sites {
+		lang := site.config.Languages[site.config.Language]
+		lang.Code = site.config.Language
+
+		// Languages at their own baseURL are on another host
+		url := site.config.RelURL("/")
+		if lang.BaseURL != "" {
+			url = site.config.AbsURL("/")
+		}
+		languages = append(languages, render.Language{Language: lang, URL: url})
+	}
+	return languages
+}
+
+// checkURLCollisions reports pages whose URLs map to the same output file
+func checkURLCollisions(pages []content.Page) error {
+	sorted := make([]content.Page, len(pages))
+	copy(sorted, pages)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	owners := map[string]string{"": "the home page"}
+	for _, page := range sorted {
+		key := strings.Trim(page.URL, "/")
+		if owner, ok := owners[key]; ok {
+			return fmt.Errorf("URL collision: %s and %s are both published at /%s", owner, page.Path, key)
+		}
+		owners[key] = page.Path
+	}
 	return nil
 }
 
@@ -224,8 +655,13 @@ func (b *Builder) copyStaticFiles(sitePath, outputPath string) error {
 				continue
 			}
 			
-			// Copy file
-			err := copyFile(copyJob.SrcPath, copyJob.DstPath)
+			// Copy file, minifying stylesheets and scripts when enabled
+			var err error
+			if minifier := b.staticMinifier(copyJob.SrcPath); minifier != nil {
+				err = minifyFile(copyJob.SrcPath, copyJob.DstPath, minifier)
+			} else {
+				err = copyFile(copyJob.SrcPath, copyJob.DstPath)
+			}
 			if err != nil {
 				errChan <- fmt.Errorf("error copying %s: %v", copyJob.SrcPath, err)
 				continue
@@ -248,6 +684,33 @@ func (b *Builder) copyStaticFiles(sitePath, outputPath string) error {
 	return nil
 }
 
+// generateCSSBundles builds and publishes the configured CSS bundles.
+// Bundles are written after static files so they replace any verbatim copy
+// of the entry file.
+func (b *Builder) generateCSSBundles() error {
+	pipeline := b.renderer.Assets()
+
+	for _, bundle := range b.config.CSSBundles {
+		// Source maps would no longer line up with minified output
+		sourceMap := bundle.SourceMap && !b.minify
+
+		r, err := pipeline.BundleCSS(bundle.Entry, bundle.Output, sourceMap)
+		if err != nil {
+			return fmt.Errorf("error bundling %s: %w", bundle.Entry, err)
+		}
+		if b.minify {
+			if r, err = pipeline.Minify(r); err != nil {
+				return fmt.Errorf("error minifying %s: %w", bundle.Entry, err)
+			}
+		}
+		if err := r.Publish(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // collectFilesToCopy collects files to copy from source directory to destination
 func collectFilesToCopy(srcDir, dstDir string) ([]interface{}, error) {
 	var jobs []interface{}
@@ -355,6 +818,7 @@ func (b *Builder) generateTagPages(outputPath string) error {
 	// Define a tag page rendering job
 	type tagRenderJob struct {
 		Tag        string
+		URL        string
 		Pages      []content.Page
 		OutputFile string
 		Title      string
@@ -379,6 +843,7 @@ func (b *Builder) generateTagPages(outputPath string) error {
 		title := fmt.Sprintf("Tag: %s", tag)
 		jobs = append(jobs, tagRenderJob{
 			Tag:        tag,
+			URL:        "tags/" + tag + "/",
 			Pages:      pages,
 			OutputFile: outputFile,
 			Title:      title,
@@ -393,7 +858,7 @@ func (b *Builder) generateTagPages(outputPath string) error {
 			renderJob := job.(tagRenderJob)
 			
 			// Render tag page
-			err := b.renderer.RenderList(renderJob.Title, renderJob.Pages, renderJob.OutputFile)
+			err := b.renderer.RenderList(renderJob.Title, renderJob.URL, renderJob.Pages, renderJob.OutputFile)
 			if err != nil {
 				errChan <- fmt.Errorf("error rendering tag page %s: %v", renderJob.Tag, err)
 				continue
@@ -417,9 +882,8 @@ func (b *Builder) generateTagPages(outputPath string) error {
 	return nil
 }
 
-// generateHomePage generates the home page
-func (b *Builder) generateHomePage(outputPath string) error {
-	// Filter and sort posts (newest first)
+// recentPosts returns all posts, newest first
+func (b *Builder) recentPosts() []content.Page {
 	posts := []content.Page{}
 	for _, page := range b.pages {
 		if page.IsPost {
@@ -430,10 +894,49 @@ func (b *Builder) generateHomePage(outputPath string) error {
 	sort.Slice(posts, func(i, j int) bool {
 		return posts[i].Date.After(posts[j].Date)
 	})
+	return posts
+}
 
+// generateHomePage generates the home page
+func (b *Builder) generateHomePage(outputPath string) error {
 	// Render home page
 	outputFile := filepath.Join(outputPath, "index.html")
-	return b.renderer.RenderHome(posts, outputFile)
+	return b.renderer.RenderHome(b.recentPosts(), outputFile)
+}
+
+// generateFeed writes index.xml, the RSS feed of the newest posts. Each
+// language of a multilingual site gets its own.
+func (b *Builder) generateFeed(outputPath string) error {
+	generator := rss.NewGenerator(b.config)
+	generator.SetMinify(b.minify)
+
+	if err := generator.Generate(b.pages, filepath.Join(outputPath, "index.xml")); err != nil {
+		return fmt.Errorf("failed to generate feed: %w", err)
+	}
+	return nil
+}
+
+// generate404Page generates 404.html in the output root, which static
+// hosts and the dev server serve for missing URLs
+func (b *Builder) generate404Page(outputPath string) error {
+	outputFile := filepath.Join(outputPath, "404.html")
+	if err := b.renderer.Render404(b.recentPosts(), outputFile); err != nil {
+		return fmt.Errorf("error rendering 404 page: %w", err)
+	}
+	return nil
+}
+
+// generateSearchIndex writes the JSON index used for client-side search
+func (b *Builder) generateSearchIndex(outputPath string) error {
+	generator, err := search.NewGenerator(b.config)
+	if err != nil {
+		return err
+	}
+
+	if err := generator.Generate(b.pages, outputPath); err != nil {
+		return fmt.Errorf("failed to generate search index: %w", err)
+	}
+	return nil
 }
 
 // copyDir recursively copies a directory tree
@@ -488,16 +991,59 @@ func copyFile(src, dst string) error {
 	return err
 }
 
+// staticMinifier returns the minifier for a static file, or nil when the
+// file should be copied verbatim
+func (b *Builder) staticMinifier(path string) minify.Minifier {
+	if !b.minify {
+		return nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".css", ".js":
+		return minify.ForExtension(path)
+	default:
+		return nil
+	}
+}
+
+// minifyFile writes a minified copy of a single file
+func minifyFile(src, dst string, minifier minify.Minifier) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, minifier(data), 0644)
+}
+
 // generateSitemap generates a sitemap.xml file for the site
 func (b *Builder) generateSitemap(outputPath string) error {
 	// Create sitemap generator
 	generator := sitemap.NewGenerator(b.config)
+	generator.SetMinify(b.minify)
 
 	// Generate sitemap.xml
 	sitemapPath := filepath.Join(outputPath, "sitemap.xml")
 
-	// Only include non-draft pages in the sitemap
-	allPages := b.pages
+	// Only include non-draft pages in the sitemap. It lists the pages of
+	// every language, so translations can be linked.
+	var allPages []content.Page
+	for _, site := range b.sites {
+		allPages = append(allPages, site.pages...)
+
+		// The home pages of other languages
+		if site != b {
+			generator.AddList(site.config.AbsURL("/"), site.pages)
+		}
+
+		// Include the tag pages
+		tags := make([]string, 0, len(site.tags))
+		for tag := range site.tags {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			generator.AddList(site.config.AbsURL("tags/"+tag+"/"), site.tags[tag])
+		}
+	}
 
 	// Log sitemap generation if not in quiet mode
 	if !b.quiet {
@@ -509,9 +1055,10 @@ func (b *Builder) generateSitemap(outputPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate sitemap: %w", err)
 	}
+	b.sitemapRoot = generator.Root()
 
 	if !b.quiet {
-		fmt.Println("Sitemap generated successfully at sitemap.xml")
+		fmt.Printf("Sitemap generated successfully at %s\n", b.sitemapRoot)
 	}
 
 	return nil
@@ -0,0 +1,805 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/templates"
+)
+
+// setupTestSite creates a minimal site with a theme and a couple of pages
+func setupTestSite(t *testing.T) (string, config.Config) {
+	t.Helper()
+
+	// Create a temporary directory for the site
+	sitePath := t.TempDir()
+
+	files := map[string]string{
+		"themes/default/layouts/base.html":   `<html><head><title>{{.Site.Title}}</title></head><body>{{block "content" .}}{{end}}</body></html>`,
+		"themes/default/layouts/single.html": `{{define "content"}}<article><h1>{{.Page.Title}}</h1>{{.Content}}</article>{{end}}`,
+		"themes/default/layouts/page.html":   `{{define "content"}}<div class="page"><h1>{{.Page.Title}}</h1>{{.Content}}</div>{{end}}`,
+		"themes/default/layouts/list.html":   `{{define "content"}}<h1>{{.Title}}</h1><ul>{{range .Pages}}<li><a href="/{{.URL}}">{{.Title}}</a></li>{{end}}</ul>{{end}}`,
+		"themes/default/layouts/home.html":   `{{define "content"}}<h1>{{.Site.Title}}</h1><ul>{{range .Pages}}<li><a href="/{{.URL}}">{{.Title}}</a></li>{{end}}</ul>{{end}}`,
+		"content/about.md": `---
+title: About
+date: 2024-01-01T00:00:00Z
+---
+About this site.`,
+		"content/posts/hello.md": `---
+title: Hello
+date: 2024-02-01T00:00:00Z
+tags: [intro]
+---
+Hello world.`,
+	}
+
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Title = "Test Site"
+	cfg.BaseURL = "https://example.com/"
+
+	return sitePath, cfg
+}
+
+func TestBuild(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Building twice must not duplicate pages
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if len(builder.pages) != 2 {
+		t.Errorf("Expected 2 pages after rebuild, got %d", len(builder.pages))
+	}
+
+	expected := []string{
+		"index.html",
+		"about/index.html",
+		"posts/hello/index.html",
+		"tags/intro/index.html",
+		"sitemap.xml",
+	}
+	for _, name := range expected {
+		if _, err := os.Stat(filepath.Join(sitePath, "public", filepath.FromSlash(name))); err != nil {
+			t.Errorf("Expected output file %s: %v", name, err)
+		}
+	}
+}
+
+func TestBuildMinify(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Minify = true
+
+	// Add a stylesheet with whitespace and comments
+	cssDir := filepath.Join(sitePath, "static", "css")
+	if err := os.MkdirAll(cssDir, 0755); err != nil {
+		t.Fatalf("Failed to create static dir: %v", err)
+	}
+	css := "/* theme */\nbody {\n  margin: 0;\n}\n"
+	if err := os.WriteFile(filepath.Join(cssDir, "style.css"), []byte(css), 0644); err != nil {
+		t.Fatalf("Failed to write stylesheet: %v", err)
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "css", "style.css"))
+	if err != nil {
+		t.Fatalf("Failed to read stylesheet: %v", err)
+	}
+	if string(data) != "body{margin:0}" {
+		t.Errorf("Expected minified stylesheet, got %q", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(sitePath, "public", "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap: %v", err)
+	}
+	if strings.Contains(string(data), "\n  <url>") {
+		t.Errorf("Expected sitemap without indentation, got %s", data)
+	}
+}
+
+func TestBuildCSSBundles(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.CSSBundles = []config.CSSBundle{{Entry: "css/main.css", Output: "css/bundle.css", SourceMap: true}}
+
+	files := map[string]string{
+		"themes/default/static/css/main.css": "@import \"reset.css\";\nbody { color: #333; }\n",
+		"static/css/reset.css":               "* { margin: 0; }\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "css", "bundle.css"))
+	if err != nil {
+		t.Fatalf("Expected bundle to be written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "* { margin: 0; }\nbody { color: #333; }\n") {
+		t.Errorf("Unexpected bundle content %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "css", "bundle.css.map")); err != nil {
+		t.Errorf("Expected source map to be written: %v", err)
+	}
+}
+
+func TestBuildCrossReferences(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+
+	files := map[string]string{
+		"content/posts/second.md": "---\ntitle: Second\nslug: number-two\n---\nBack to [hello](hello.md#top).",
+		"layouts/page.html":       `{{define "content"}}<a class="ref" href="{{relref . "posts/second.md"}}">Second</a>{{.Content}}{{end}}`,
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "posts", "number-two", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected post to be written: %v", err)
+	}
+	if !strings.Contains(string(data), `<a href="/posts/hello/#top">hello</a>`) {
+		t.Errorf("Expected the Markdown link to be resolved, got:\n%s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(sitePath, "public", "about", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected page to be written: %v", err)
+	}
+	if !strings.Contains(string(data), `<a class="ref" href="/posts/number-two/">`) {
+		t.Errorf("Expected relref to be resolved, got:\n%s", data)
+	}
+
+	// A reference to a missing file fails the build with its location
+	broken := filepath.Join(sitePath, "content", "posts", "broken.md")
+	if err := os.WriteFile(broken, []byte("---\ntitle: Broken\n---\nSee [gone](gone.md)."), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	err = builder.Build(sitePath)
+	if err == nil || !strings.Contains(err.Error(), broken+":4: unresolved reference \"gone.md\"") {
+		t.Errorf("Expected an unresolved reference error, got %v", err)
+	}
+}
+
+func TestBuildPermalinks(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Permalinks = map[string]string{"posts": "/blog/:year/:month/:slug/"}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "blog", "2024", "02", "hello", "index.html")); err != nil {
+		t.Errorf("Expected post at its permalink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "about", "index.html")); err != nil {
+		t.Errorf("Expected pages outside the section to keep their URL: %v", err)
+	}
+
+	sitemap, err := os.ReadFile(filepath.Join(sitePath, "public", "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Expected sitemap to be written: %v", err)
+	}
+	if !strings.Contains(string(sitemap), "<loc>https://example.com/blog/2024/02/hello/</loc>") {
+		t.Errorf("Expected sitemap to use the permalink, got:\n%s", sitemap)
+	}
+
+	// A second post on the same day with the same slug collides
+	collision := filepath.Join(sitePath, "content", "posts", "hello-again.md")
+	if err := os.WriteFile(collision, []byte("---\ntitle: Hello again\nslug: hello\ndate: 2024-02-20T00:00:00Z\n---\nAgain."), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	err = builder.Build(sitePath)
+	if err == nil || !strings.Contains(err.Error(), "URL collision") || !strings.Contains(err.Error(), "/blog/2024/02/hello") {
+		t.Errorf("Expected a URL collision error, got %v", err)
+	}
+}
+
+func TestBuildPermalinksRelativeSitePath(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Permalinks = map[string]string{"posts": "/blog/:year/:slug/"}
+
+	// Build from inside the site, as `scribe build` does, with content in
+	// a custom directory
+	if err := os.Rename(filepath.Join(sitePath, "content"), filepath.Join(sitePath, "src")); err != nil {
+		t.Fatalf("Failed to move content: %v", err)
+	}
+	cfg.ContentDir = "src"
+	t.Chdir(sitePath)
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build("."); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join("public", "blog", "2024", "hello", "index.html")); err != nil {
+		t.Errorf("Expected post at its permalink: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("public", "hello", "index.html")); err == nil {
+		t.Errorf("Expected the post not to be written at the content root")
+	}
+}
+
+func TestBuildSubdirectoryBaseURL(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.BaseURL = "https://example.com/docs/"
+
+	listTemplate := `{{define "content"}}<ul>{{range .Pages}}<li><a href="{{.RelPermalink}}">{{.Title}}</a></li>{{end}}</ul><a href="{{relURL "about/"}}">About</a>{{end}}`
+	if err := os.WriteFile(filepath.Join(sitePath, "themes", "default", "layouts", "home.html"), []byte(listTemplate), 0644); err != nil {
+		t.Fatalf("Failed to write home template: %v", err)
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	home, err := os.ReadFile(filepath.Join(sitePath, "public", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected home page to be written: %v", err)
+	}
+	for _, want := range []string{`href="/docs/posts/hello/"`, `href="/docs/about/"`} {
+		if !strings.Contains(string(home), want) {
+			t.Errorf("Expected home page to contain %s, got:\n%s", want, home)
+		}
+	}
+
+	// Files are still written relative to the output directory
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "posts", "hello", "index.html")); err != nil {
+		t.Errorf("Expected post in the output directory: %v", err)
+	}
+}
+
+func TestBuild404Page(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Without a 404 layout the embedded default is used inside base.html
+	page, err := os.ReadFile(filepath.Join(sitePath, "public", "404.html"))
+	if err != nil {
+		t.Fatalf("Expected 404.html to be written: %v", err)
+	}
+	for _, want := range []string{"<title>Test Site</title>", "Page Not Found", `href="/posts/hello/"`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Expected 404 page to contain %s, got:\n%s", want, page)
+		}
+	}
+
+	// A 404 layout in the site overrides the default
+	layout := filepath.Join(sitePath, "layouts", "404.html")
+	if err := os.MkdirAll(filepath.Dir(layout), 0755); err != nil {
+		t.Fatalf("Failed to create layouts dir: %v", err)
+	}
+	if err := os.WriteFile(layout, []byte(`{{define "content"}}<p>Lost? {{.Title}}</p>{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to write 404 layout: %v", err)
+	}
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	page, err = os.ReadFile(filepath.Join(sitePath, "public", "404.html"))
+	if err != nil {
+		t.Fatalf("Expected 404.html to be written: %v", err)
+	}
+	if !strings.Contains(string(page), "<p>Lost? Page Not Found</p>") {
+		t.Errorf("Expected the site's 404 layout, got:\n%s", page)
+	}
+}
+
+func TestBuildRobotsTXT(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.EnableRobotsTXT = true
+
+	tests := []struct {
+		environment string
+		want        string
+	}{
+		{"production", "User-agent: *\nDisallow:\n\nSitemap: https://example.com/sitemap.xml\n"},
+		{"development", "User-agent: *\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n"},
+	}
+
+	for _, tt := range tests {
+		cfg.Environment = tt.environment
+		builder := NewBuilder(cfg)
+		builder.SetQuiet(true)
+		if err := builder.Build(sitePath); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+
+		robots, err := os.ReadFile(filepath.Join(sitePath, "public", "robots.txt"))
+		if err != nil {
+			t.Fatalf("Expected robots.txt to be written: %v", err)
+		}
+		if string(robots) != tt.want {
+			t.Errorf("%s: Expected robots.txt %q, got %q", tt.environment, tt.want, robots)
+		}
+	}
+
+	// A layout replaces the default and is not HTML escaped
+	layout := filepath.Join(sitePath, "themes", "default", "layouts", "robots.txt")
+	if err := os.WriteFile(layout, []byte("# {{.Site.Title}} & friends\nSitemap: {{absURL \"sitemap.xml\"}}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write robots.txt layout: %v", err)
+	}
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	robots, err := os.ReadFile(filepath.Join(sitePath, "public", "robots.txt"))
+	if err != nil {
+		t.Fatalf("Expected robots.txt to be written: %v", err)
+	}
+	if want := "# Test Site & friends\nSitemap: https://example.com/sitemap.xml\n"; string(robots) != want {
+		t.Errorf("Expected robots.txt %q, got %q", want, robots)
+	}
+}
+
+func TestBuildSitemapGitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	sitePath, cfg := setupTestSite(t)
+	cfg.EnableGitInfo = true
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = sitePath
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2024-05-06T10:00:00Z", "GIT_AUTHOR_DATE=2024-05-06T10:00:00Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "content/posts/hello.md")
+	git("commit", "-q", "-m", "Add post")
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Expected sitemap to be written: %v", err)
+	}
+	sitemap := string(data)
+
+	for _, want := range []string{
+		// Committed files use the commit date
+		"<loc>https://example.com/posts/hello/</loc>\n    <lastmod>2024-05-06</lastmod>",
+		// Uncommitted files fall back to their date
+		"<loc>https://example.com/about/</loc>\n    <lastmod>2024-01-01</lastmod>",
+		"<loc>https://example.com/tags/intro/</loc>\n    <lastmod>2024-05-06</lastmod>",
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("Expected sitemap to contain %q, got:\n%s", want, sitemap)
+		}
+	}
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.SearchIndex = config.SearchIndex{Enable: true, Fields: []string{"title", "url"}}
+
+	// Layouts include partials by path
+	files := map[string]string{
+		"themes/default/layouts/partials/search.html": `<div id="search" data-index="{{relURL "search.json"}}"></div>`,
+		"themes/default/layouts/home.html":            `{{define "content"}}{{template "partials/search.html" .}}{{end}}`,
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(sitePath, "public", "search.json"))
+	if err != nil {
+		t.Fatalf("Expected search.json to be written: %v", err)
+	}
+	if want := `[{"title":"About","url":"/about/"},{"title":"Hello","url":"/posts/hello/"}]`; string(index) != want {
+		t.Errorf("Expected search index %s, got %s", want, index)
+	}
+
+	home, err := os.ReadFile(filepath.Join(sitePath, "public", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected home page to be written: %v", err)
+	}
+	if !strings.Contains(string(home), `<div id="search" data-index="/search.json"></div>`) {
+		t.Errorf("Expected home page to include the search partial, got:\n%s", home)
+	}
+}
+
+func TestBuildSEOTemplates(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Author = "Jane Doe"
+	cfg.Params = map[string]interface{}{"twitterHandle": "janedoe"}
+
+	base := `<html><head>{{template "_internal/canonical.html" .}}{{template "_internal/opengraph.html" .}}{{template "_internal/twitter_cards.html" .}}{{template "_internal/schema.html" .}}</head><body>{{block "content" .}}{{end}}</body></html>`
+	files := map[string]string{
+		"themes/default/layouts/base.html": base,
+		"content/posts/trip/index.md": `---
+title: Trip
+description: Notes from the road
+date: 2024-03-01T00:00:00Z
+tags: [travel]
+images: [cover.jpg, /images/shared.jpg]
+canonical: https://elsewhere.example.com/trip/
+---
+On the road.`,
+		"content/posts/trip/cover.jpg": "not really a JPEG",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	post, err := os.ReadFile(filepath.Join(sitePath, "public", "posts", "trip", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected post to be written: %v", err)
+	}
+	for _, want := range []string{
+		`<link rel="canonical" href="https://elsewhere.example.com/trip/">`,
+		`<meta property="og:title" content="Trip">`,
+		`<meta property="og:description" content="Notes from the road">`,
+		`<meta property="og:url" content="https://elsewhere.example.com/trip/">`,
+		`<meta property="og:image" content="https://example.com/posts/trip/cover.jpg">`,
+		`<meta property="og:image" content="https://example.com/images/shared.jpg">`,
+		`<meta property="og:type" content="article">`,
+		`<meta property="article:tag" content="travel">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<meta name="twitter:site" content="@janedoe">`,
+		`"@type": "BlogPosting"`,
+		`"headline": "Trip"`,
+		`"datePublished": "2024-03-01T00:00:00Z"`,
+		`"author": {"@type": "Person", "name": "Jane Doe"}`,
+	} {
+		if !strings.Contains(string(post), want) {
+			t.Errorf("Expected post to contain %s, got:\n%s", want, post)
+		}
+	}
+
+	// Bundle images used in front matter are published
+	if _, err := os.Stat(filepath.Join(sitePath, "public", "posts", "trip", "cover.jpg")); err != nil {
+		t.Errorf("Expected the cover image to be published: %v", err)
+	}
+
+	home, err := os.ReadFile(filepath.Join(sitePath, "public", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected home page to be written: %v", err)
+	}
+	for _, want := range []string{
+		`<link rel="canonical" href="https://example.com/">`,
+		`<meta property="og:type" content="website">`,
+		`"@type": "WebSite"`,
+	} {
+		if !strings.Contains(string(home), want) {
+			t.Errorf("Expected home page to contain %s, got:\n%s", want, home)
+		}
+	}
+
+	tag, err := os.ReadFile(filepath.Join(sitePath, "public", "tags", "travel", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected tag page to be written: %v", err)
+	}
+	if !strings.Contains(string(tag), `<link rel="canonical" href="https://example.com/tags/travel/">`) {
+		t.Errorf("Expected tag page to have a canonical link, got:\n%s", tag)
+	}
+}
+
+func TestBuildMenus(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Menus = map[string][]config.MenuEntry{
+		"main": {
+			{Name: "Home", URL: "/", Weight: 1},
+			{Name: "About", PageRef: "about.md", Weight: 2},
+		},
+	}
+
+	base := `<html><body><nav>{{range .Site.Menus.main}}<a href="{{.URL}}"{{if .Active}} class="active"{{end}}>{{.Name}}</a>{{end}}</nav>{{block "content" .}}{{end}}</body></html>`
+	files := map[string]string{
+		"themes/default/layouts/base.html": base,
+		"content/posts/hello.md": `---
+title: Hello
+menu:
+  main:
+    name: Blog
+    weight: 3
+---
+Hello world.`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(sitePath, filepath.FromSlash(name)), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	about, err := os.ReadFile(filepath.Join(sitePath, "public", "about", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected about page to be written: %v", err)
+	}
+	want := `<nav><a href="/">Home</a><a href="/about/" class="active">About</a><a href="/posts/hello/">Blog</a></nav>`
+	if !strings.Contains(string(about), want) {
+		t.Errorf("Expected about page to contain %s, got:\n%s", want, about)
+	}
+
+	cfg.Menus["main"] = append(cfg.Menus["main"], config.MenuEntry{Name: "Missing", PageRef: "missing.md"})
+	builder = NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err == nil || !strings.Contains(err.Error(), "missing.md") {
+		t.Errorf("Expected an unresolved pageRef to fail the build, got %v", err)
+	}
+}
+
+func TestBuildDataFiles(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+
+	files := map[string]string{
+		"themes/default/layouts/home.html": `{{define "content"}}{{range .Site.Data.team.members}}<p>{{.name}}: {{.role}}</p>{{end}}<footer>{{.Site.Data.footer.text}}</footer>{{end}}`,
+		"themes/default/data/footer.toml":  `text = "Theme footer"`,
+		"data/team/members.yml":            "- name: Ada\n  role: Engineer\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	home, err := os.ReadFile(filepath.Join(sitePath, "public", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected home page to be written: %v", err)
+	}
+	for _, want := range []string{"<p>Ada: Engineer</p>", "<footer>Theme footer</footer>"} {
+		if !strings.Contains(string(home), want) {
+			t.Errorf("Expected home page to contain %s, got:\n%s", want, home)
+		}
+	}
+
+	// A broken data file fails the build
+	if err := os.WriteFile(filepath.Join(sitePath, "data", "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write broken.json: %v", err)
+	}
+	if err := builder.Build(sitePath); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Errorf("Expected the build to fail on broken.json, got %v", err)
+	}
+}
+
+func TestBuildMultilingual(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Languages = map[string]config.Language{
+		"en": {LanguageName: "English", Weight: 1},
+		"de": {LanguageName: "Deutsch", Weight: 2, Title: "Testseite"},
+		"fr": {LanguageName: "Français", Weight: 3, ContentDir: "content/fr"},
+	}
+	cfg.SearchIndex.Enable = true
+
+	files := map[string]string{
+		"themes/default/layouts/single.html":          `{{define "content"}}<h1>{{.Page.Title}}</h1><p>{{i18n "readMore"}}</p>{{range .Page.Translations}}<a hreflang="{{.Lang}}" href="{{.Permalink}}">{{.Title}}</a>{{end}}{{end}}`,
+		"themes/default/layouts/home.html":            `{{define "content"}}<h1>{{.Site.Title}}</h1>{{range .Site.Languages}}<a href="{{.URL}}"{{if .Current}} class="current"{{end}}>{{.LanguageName}}</a>{{end}}<ul>{{range .Pages}}<li>{{.Title}}</li>{{end}}</ul>{{template "partials/search.html" .}}{{end}}`,
+		"themes/default/layouts/partials/search.html": templates.SearchPartial,
+		"content/posts/hello.de.md": `---
+title: Hallo
+date: 2024-02-01T00:00:00Z
+tags: [einführung]
+---
+Hallo Welt.`,
+		"content/fr/posts/bonjour.md": `---
+title: Bonjour
+date: 2024-02-01T00:00:00Z
+translationKey: posts/hello
+---
+Bonjour le monde.`,
+		"i18n/en.yml": "readMore: Read more\n",
+		"i18n/de.yml": "readMore: Weiterlesen\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(sitePath, "public", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"posts/hello/index.html", []string{"<h1>Hello</h1>", "<p>Read more</p>", `hreflang="de" href="https://example.com/de/posts/hello/"`, `hreflang="fr"`}},
+		{"de/posts/hello/index.html", []string{"<h1>Hallo</h1>", "<p>Weiterlesen</p>", `hreflang="en"`, `hreflang="fr"`}},
+		{"fr/posts/bonjour/index.html", []string{"<h1>Bonjour</h1>", "<p>Read more</p>", `hreflang="de"`}},
+		{"de/index.html", []string{"<h1>Testseite</h1>", "<li>Hallo</li>", `<a href="/de/" class="current">Deutsch</a>`, `<a href="/">English</a>`}},
+		{"de/tags/einführung/index.html", []string{"Hallo"}},
+		{"index.html", []string{`fetch("/search.json")`}},
+		{"de/index.html", []string{`fetch("/de/search.json")`}},
+		{"de/search.json", []string{`"title":"Hallo"`}},
+		{"index.xml", []string{"<title>Hello</title>", "<link>https://example.com/posts/hello/</link>", "<language>en</language>"}},
+		{"de/index.xml", []string{"<title>Testseite</title>", "<title>Hallo</title>", "<link>https://example.com/de/posts/hello/</link>", "<language>de</language>"}},
+		{"fr/index.xml", []string{"<title>Bonjour</title>"}},
+		{"sitemap.xml", []string{"https://example.com/fr/posts/bonjour/", `hreflang="de"`}},
+	}
+	for _, tt := range tests {
+		out := read(tt.file)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %s to contain %s, got:\n%s", tt.file, want, out)
+			}
+		}
+	}
+
+	// Pages in other languages are left out of the default language
+	for _, name := range []string{"posts/hello.de/index.html", "fr/posts/hello/index.html", "tags/einführung/index.html"} {
+		if _, err := os.Stat(filepath.Join(sitePath, "public", filepath.FromSlash(name))); err == nil {
+			t.Errorf("Expected %s not to be written", name)
+		}
+	}
+	if out := read("fr/index.html"); strings.Contains(out, "About") {
+		t.Errorf("Expected the French site to leave out untranslated pages, got:\n%s", out)
+	}
+}
+
+func TestBuildPrevNextAndRelated(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+
+	files := map[string]string{
+		"themes/default/layouts/single.html": `{{define "content"}}<h1>{{.Page.Title}}</h1>{{with .Page.PrevInSection}}<a rel="prev" href="{{.RelPermalink}}">{{.Title}}</a>{{end}}{{with .Page.NextInSection}}<a rel="next" href="{{.RelPermalink}}">{{.Title}}</a>{{end}}{{with .Page.Next}}<span class="next">{{.Title}}</span>{{end}}<ul>{{range .Site.RegularPages.Related .Page}}<li>{{.Title}}</li>{{end}}</ul>{{end}}`,
+		"content/posts/world.md": `---
+title: World
+date: 2024-03-01T00:00:00Z
+tags: [intro, go]
+---
+World.`,
+		"content/posts/later.md": `---
+title: Later
+date: 2024-04-01T00:00:00Z
+tags: [go]
+---
+Later.`,
+	}
+	for name, data := range files {
+		path := filepath.Join(sitePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"posts/hello/index.html", []string{`<a rel="next" href="/posts/world/">World</a>`, `<span class="next">World</span>`, "<ul><li>World</li></ul>"}},
+		{"posts/world/index.html", []string{`<a rel="prev" href="/posts/hello/">Hello</a>`, `<a rel="next" href="/posts/later/">Later</a>`, "<ul><li>Hello</li><li>Later</li></ul>"}},
+		{"posts/later/index.html", []string{`<a rel="prev" href="/posts/world/">World</a>`, "<ul><li>World</li></ul>"}},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(sitePath, "public", filepath.FromSlash(tt.file)))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", tt.file, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("Expected %s to contain %s, got:\n%s", tt.file, want, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitLastmod returns the time of the last commit touching each file under
+// dir, keyed by dir joined with the file's path. It reads the history with
+// a single git log so the cost doesn't grow with the number of pages.
+func gitLastmod(dir string) (map[string]time.Time, error) {
+	// -z lists file names verbatim, NUL terminated, instead of quoting
+	// names with spaces or non-ASCII characters
+	cmd := exec.Command("git", "-c", "core.quotepath=false", "log", "-z", "--format=%x01%cI", "--name-only", "--relative", "--", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading git history of %s: %v: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Commits are listed newest first, so the first time a file appears
+	// is its last modification. Each commit is its date, marked with
+	// \x01, followed by a line break and the names of its files.
+	lastmod := make(map[string]time.Time)
+	var commitTime time.Time
+	for _, field := range strings.Split(string(out), "\x00") {
+		if strings.HasPrefix(field, "\x01") {
+			commitTime, err = time.Parse(time.RFC3339, strings.TrimPrefix(field, "\x01"))
+			if err != nil {
+				return nil, fmt.Errorf("error reading git history: %w", err)
+			}
+			continue
+		}
+		name := strings.TrimPrefix(field, "\n")
+		if name == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if _, ok := lastmod[path]; !ok {
+			lastmod[path] = commitTime
+		}
+	}
+	return lastmod, nil
+}
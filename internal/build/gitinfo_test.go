@@ -0,0 +1,69 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGitLastmod(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date, "GIT_AUTHOR_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	git("2024-05-06T10:00:00Z", "init", "-q")
+	write("über.md", "Über")
+	write("posts/first post.md", "First")
+	write("posts/second.md", "Second")
+	git("2024-05-06T10:00:00Z", "add", ".")
+	git("2024-05-06T10:00:00Z", "commit", "-q", "-m", "Add pages")
+	write("posts/second.md", "Second, edited")
+	git("2024-06-01T08:00:00Z", "commit", "-q", "-am", "Edit")
+
+	lastmod, err := gitLastmod(dir)
+	if err != nil {
+		t.Fatalf("gitLastmod failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"über.md", "2024-05-06"},
+		{"posts/first post.md", "2024-05-06"},
+		{"posts/second.md", "2024-06-01"},
+	}
+	for _, tt := range tests {
+		got, ok := lastmod[filepath.Join(dir, filepath.FromSlash(tt.name))]
+		if !ok {
+			t.Errorf("Expected a lastmod for %s, got %v", tt.name, lastmod)
+			continue
+		}
+		if got.UTC().Format(time.DateOnly) != tt.want {
+			t.Errorf("Expected lastmod %s for %s, got %s", tt.want, tt.name, got)
+		}
+	}
+}
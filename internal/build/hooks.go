@@ -0,0 +1,99 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Hook stages passed to commands through SCRIBE_HOOK
+const (
+	hookPreBuild  = "preBuild"
+	hookPostBuild = "postBuild"
+)
+
+// runHooks runs the configured commands for a build stage in order.
+// Each command runs through the system shell from the site directory, and
+// the first failing command aborts the build with its stderr attached.
+func (b *Builder) runHooks(stage string, commands []string, sitePath, outputPath string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env := append(os.Environ(), b.hookEnv(stage, sitePath, outputPath)...)
+
+	for _, command := range commands {
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+
+		if !b.quiet {
+			fmt.Printf("Running %s hook: %s\n", stage, command)
+		}
+
+		cmd := shellCommand(command)
+		cmd.Dir = sitePath
+		cmd.Env = env
+
+		var stderr bytes.Buffer
+		if b.quiet {
+			cmd.Stdout = io.Discard
+			cmd.Stderr = &stderr
+		} else {
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+		}
+
+		if err := cmd.Run(); err != nil {
+			output := strings.TrimSpace(stderr.String())
+			if output == "" {
+				return fmt.Errorf("%s hook %q failed: %w", stage, command, err)
+			}
+			return fmt.Errorf("%s hook %q failed: %w\n%s", stage, command, err, output)
+		}
+	}
+
+	return nil
+}
+
+// hookEnv returns the environment variables describing the current build
+func (b *Builder) hookEnv(stage, sitePath, outputPath string) []string {
+	absSite, err := filepath.Abs(sitePath)
+	if err != nil {
+		absSite = sitePath
+	}
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		absOutput = outputPath
+	}
+
+	devMode := "false"
+	if b.devMode {
+		devMode = "true"
+	}
+
+	return []string{
+		"SCRIBE_HOOK=" + stage,
+		"SCRIBE_SITE_PATH=" + absSite,
+		"SCRIBE_OUTPUT_DIR=" + absOutput,
+		"SCRIBE_CONTENT_DIR=" + filepath.Join(absSite, b.config.ContentDir),
+		"SCRIBE_STATIC_DIR=" + filepath.Join(absSite, b.config.StaticDir),
+		"SCRIBE_BASE_URL=" + b.config.BaseURL,
+		"SCRIBE_DEV_MODE=" + devMode,
+		// Newline separated so paths containing spaces survive
+		"SCRIBE_CHANGED_FILES=" + strings.Join(b.changedFiles, "\n"),
+	}
+}
+
+// shellCommand wraps a hook command line in the platform shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,65 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+
+	sitePath, cfg := setupTestSite(t)
+	cfg.Hooks.PreBuild = []string{`mkdir -p static && echo "$SCRIBE_HOOK" > static/hook.txt`}
+	cfg.Hooks.PostBuild = []string{`echo "$SCRIBE_CHANGED_FILES" > "$SCRIBE_OUTPUT_DIR/post.txt"`}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	builder.SetChangedFiles([]string{"content/about.md"})
+
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// The pre-build hook output should have been copied as a static file
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "hook.txt"))
+	if err != nil {
+		t.Fatalf("Expected pre-build hook output to be copied: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "preBuild" {
+		t.Errorf("Expected SCRIBE_HOOK to be preBuild, got %q", data)
+	}
+
+	// The post-build hook should see the changed files
+	data, err = os.ReadFile(filepath.Join(sitePath, "public", "post.txt"))
+	if err != nil {
+		t.Fatalf("Expected post-build hook output: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "content/about.md" {
+		t.Errorf("Expected changed files to be passed to hook, got %q", data)
+	}
+}
+
+func TestBuildHookFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+
+	sitePath, cfg := setupTestSite(t)
+	cfg.Hooks.PreBuild = []string{`echo "tailwind exploded" >&2; exit 3`}
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+
+	err := builder.Build(sitePath)
+	if err == nil {
+		t.Fatal("Expected failing hook to fail the build")
+	}
+	if !strings.Contains(err.Error(), "tailwind exploded") {
+		t.Errorf("Expected error to include hook stderr, got: %v", err)
+	}
+}
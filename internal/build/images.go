@@ -0,0 +1,166 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dikaio/scribe/internal/assets"
+	"github.com/dikaio/scribe/internal/content"
+	"github.com/dikaio/scribe/internal/images"
+)
+
+// defaultImageWidths are the srcset widths used when imaging.widths isn't set
+var defaultImageWidths = []int{480, 800, 1200, 1600}
+
+// imageRenderer returns the renderer for the Markdown images of page.
+// Local images get their dimensions and a srcset of resized copies.
+func (b *Builder) imageRenderer(page content.Page) content.ImageRenderer {
+	return func(src, alt, title string) (string, error) {
+		r, err := b.resolveImage(page, src)
+		if err != nil {
+			return "", fmt.Errorf("image %q: %w", src, err)
+		}
+		if r == nil {
+			// Not a local image, keep the plain tag
+			return "", nil
+		}
+
+		width, err := r.Width()
+		if err != nil {
+			return "", err
+		}
+		height, err := r.Height()
+		if err != nil {
+			return "", err
+		}
+		srcURL, err := r.RelPermalink()
+		if err != nil {
+			return "", err
+		}
+
+		srcset, err := b.imageSrcset(r, width)
+		if err != nil {
+			return "", err
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, `<img src="%s" alt="%s"`, html.EscapeString(srcURL), html.EscapeString(alt))
+		if title != "" {
+			fmt.Fprintf(&sb, ` title="%s"`, html.EscapeString(title))
+		}
+		fmt.Fprintf(&sb, ` width="%d" height="%d"`, width, height)
+		if srcset != "" {
+			sizes := b.config.Imaging.Sizes
+			if sizes == "" {
+				sizes = fmt.Sprintf("(max-width: %dpx) 100vw, %dpx", width, width)
+			}
+			fmt.Fprintf(&sb, ` srcset="%s" sizes="%s"`, html.EscapeString(srcset), html.EscapeString(sizes))
+		}
+		sb.WriteString(` loading="lazy" decoding="async">`)
+		return sb.String(), nil
+	}
+}
+
+// resolveImage finds the resource behind an image reference. Relative
+// references are looked up in the page bundle and absolute ones in the
+// assets and static directories. It returns nil for remote images, files
+// that don't exist and references leading out of the page bundle, which
+// are left as written.
+func (b *Builder) resolveImage(page content.Page, src string) (*assets.Resource, error) {
+	lower := strings.ToLower(src)
+	if strings.HasPrefix(lower, "http:") || strings.HasPrefix(lower, "https:") ||
+		strings.HasPrefix(lower, "//") || strings.HasPrefix(lower, "data:") {
+		return nil, nil
+	}
+	if strings.ContainsAny(src, "?#") || !images.IsImage(path.Ext(src)) {
+		return nil, nil
+	}
+
+	pipeline := b.renderer.Assets()
+
+	var r *assets.Resource
+	var err error
+	if strings.HasPrefix(src, "/") {
+		r, err = pipeline.Get(src)
+	} else {
+		r, err = pipeline.PageResources(page.Path, page.URL).Get(src)
+	}
+	if errors.Is(err, assets.ErrNotFound) || errors.Is(err, assets.ErrOutsideBundle) {
+		return nil, nil
+	}
+	return r, err
+}
+
+// resolvePageImages publishes the front matter images of a page that are
+// relative to its bundle and replaces them with their permalinks
+func (b *Builder) resolvePageImages(page *content.Page) error {
+	if len(page.Images) == 0 {
+		return nil
+	}
+
+	resolved := make([]string, len(page.Images))
+	for i, src := range page.Images {
+		lower := strings.ToLower(src)
+		if strings.HasPrefix(src, "/") || strings.HasPrefix(lower, "http:") || strings.HasPrefix(lower, "https:") {
+			resolved[i] = src
+			continue
+		}
+
+		r, err := b.renderer.Assets().PageResources(page.Path, page.URL).Get(src)
+		if err != nil {
+			return fmt.Errorf("%s: image %q: %w", page.Path, src, err)
+		}
+		if resolved[i], err = r.Permalink(); err != nil {
+			return fmt.Errorf("%s: image %q: %w", page.Path, src, err)
+		}
+	}
+	page.Images = resolved
+	return nil
+}
+
+// imageSrcset resizes r to each configured width smaller than the original
+// and returns the srcset attribute value
+func (b *Builder) imageSrcset(r *assets.Resource, width int) (string, error) {
+	// Animated GIFs would lose their frames
+	if strings.EqualFold(path.Ext(r.Name()), ".gif") {
+		return "", nil
+	}
+
+	widths := b.config.Imaging.Widths
+	if len(widths) == 0 {
+		widths = defaultImageWidths
+	}
+	widths = append([]int(nil), widths...)
+	sort.Ints(widths)
+
+	var candidates []string
+	for _, w := range widths {
+		if w <= 0 || w >= width {
+			continue
+		}
+		resized, err := r.Resize(fmt.Sprintf("%dx", w))
+		if err != nil {
+			return "", err
+		}
+		url, err := resized.RelPermalink()
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, fmt.Sprintf("%s %dw", url, w))
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	// The original covers the largest displays
+	url, err := r.RelPermalink()
+	if err != nil {
+		return "", err
+	}
+	candidates = append(candidates, fmt.Sprintf("%s %dw", url, width))
+	return strings.Join(candidates, ", "), nil
+}
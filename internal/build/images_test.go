@@ -0,0 +1,77 @@
+package build
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePNG writes a blank PNG of the given size
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+}
+
+func TestBuildResponsiveImages(t *testing.T) {
+	sitePath, cfg := setupTestSite(t)
+	cfg.Imaging.Widths = []int{100, 200, 400}
+
+	bundle := filepath.Join(sitePath, "content", "posts", "trip")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatalf("Failed to create bundle: %v", err)
+	}
+	page := "---\ntitle: Trip\n---\n![Beach](beach.png)\n\n![Logo](/images/logo.png)\n\n![Remote](https://example.com/x.png)\n\n![Shared](../shared/beach.png)\n"
+	if err := os.WriteFile(filepath.Join(bundle, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	writePNG(t, filepath.Join(bundle, "beach.png"), 300, 150)
+	// Inside the bundle, where ../shared/beach.png must not lead
+	writePNG(t, filepath.Join(bundle, "shared", "beach.png"), 300, 150)
+	writePNG(t, filepath.Join(sitePath, "static", "images", "logo.png"), 64, 32)
+
+	builder := NewBuilder(cfg)
+	builder.SetQuiet(true)
+	if err := builder.Build(sitePath); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sitePath, "public", "posts", "trip", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected bundle page to be written: %v", err)
+	}
+	html := string(data)
+
+	expected := []string{
+		`<img src="/posts/trip/beach.png" alt="Beach" width="300" height="150" srcset="/posts/trip/beach_resize_100x0_`,
+		`100w, /posts/trip/beach_resize_200x0_`,
+		`200w, /posts/trip/beach.png 300w" sizes="(max-width: 300px) 100vw, 300px" loading="lazy" decoding="async">`,
+		`<img src="/images/logo.png" alt="Logo" width="64" height="32" loading="lazy" decoding="async">`,
+		`<img src="https://example.com/x.png" alt="Remote" loading="lazy" decoding="async">`,
+		`<img src="../shared/beach.png" alt="Shared" loading="lazy" decoding="async">`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(html, s) {
+			t.Errorf("Expected page to contain %q, got:\n%s", s, html)
+		}
+	}
+
+	// The original and the resized copies are published next to the page
+	matches, _ := filepath.Glob(filepath.Join(sitePath, "public", "posts", "trip", "beach*.png"))
+	if len(matches) != 3 {
+		t.Errorf("Expected 3 published images, got %v", matches)
+	}
+}
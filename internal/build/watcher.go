@@ -9,7 +9,6 @@ import (
 
 // Watcher watches for file changes
 type Watcher struct {
-	builder     *Builder
 	sitePath    string
 	lastBuild   time.Time
 	changedDirs map[string]time.Time
@@ -17,9 +16,8 @@ type Watcher struct {
 }
 
 // NewWatcher creates a new file watcher
-func NewWatcher(builder *Builder, sitePath string) *Watcher {
+func NewWatcher(sitePath string) *Watcher {
 	return &Watcher{
-		builder:     builder,
 		sitePath:    sitePath,
 		lastBuild:   time.Now(),
 		changedDirs: make(map[string]time.Time),
@@ -32,25 +30,26 @@ func (w *Watcher) SetQuiet(quiet bool) {
 	w.quiet = quiet
 }
 
-// Watch starts watching for file changes
-func (w *Watcher) Watch(interval time.Duration, rebuild func() error) error {
+// Watch starts watching for file changes, calling rebuild with the files
+// that changed
+func (w *Watcher) Watch(interval time.Duration, rebuild func(changed []string) error) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Message moved to server.go
 
 	for range ticker.C {
-		changed, err := w.checkForChanges()
+		changedFiles, err := w.checkForChanges()
 		if err != nil {
 			return err
 		}
 
-		if changed {
+		if len(changedFiles) > 0 {
 			if !w.quiet {
 				fmt.Println("Changes detected, rebuilding...")
 			}
-			
-			if err := rebuild(); err != nil {
+
+			if err := rebuild(changedFiles); err != nil {
 				// Always show errors, even in quiet mode
 				fmt.Printf("Error rebuilding: %s\n", err)
 			} else {
@@ -65,58 +64,65 @@ func (w *Watcher) Watch(interval time.Duration, rebuild func() error) error {
 	return nil
 }
 
-// checkForChanges checks if any files have changed since the last build
-func (w *Watcher) checkForChanges() (bool, error) {
+// checkForChanges returns the files that have changed since the last build
+func (w *Watcher) checkForChanges() ([]string, error) {
 	// Directories to watch
 	dirsToWatch := []string{
 		filepath.Join(w.sitePath, "content"),
 		filepath.Join(w.sitePath, "layouts"),
 		filepath.Join(w.sitePath, "static"),
+		filepath.Join(w.sitePath, "assets"),
+		filepath.Join(w.sitePath, "data"),
+		filepath.Join(w.sitePath, "i18n"),
 		filepath.Join(w.sitePath, "themes"),
+		filepath.Join(w.sitePath, "config"),
+		filepath.Join(w.sitePath, "config.yml"),
 		filepath.Join(w.sitePath, "config.jsonc"),
+		filepath.Join(w.sitePath, "config.json"),
 	}
 
-	changed := false
+	var changed []string
 
 	// Check each directory
 	for _, dir := range dirsToWatch {
-		fileChanged, err := w.hasChanged(dir)
+		files, err := w.changedFiles(dir)
 		if err != nil {
 			// Skip if directory doesn't exist
 			if os.IsNotExist(err) {
 				continue
 			}
-			return false, err
+			return nil, err
 		}
 
-		if fileChanged {
-			changed = true
-		}
+		changed = append(changed, files...)
 	}
 
 	return changed, nil
 }
 
-// hasChanged checks if a file or directory has changed
-func (w *Watcher) hasChanged(path string) (bool, error) {
+// changedFiles returns the changed files for a file or directory
+func (w *Watcher) changedFiles(path string) ([]string, error) {
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Check if it's a directory
 	if info.IsDir() {
-		return w.dirHasChanged(path)
+		return w.dirChangedFiles(path)
 	}
 
 	// Check if file has changed
-	return info.ModTime().After(w.lastBuild), nil
+	if info.ModTime().After(w.lastBuild) {
+		return []string{path}, nil
+	}
+	return nil, nil
 }
 
-// dirHasChanged checks if any files in a directory have changed
-func (w *Watcher) dirHasChanged(dir string) (bool, error) {
-	changed := false
+// dirChangedFiles returns the files in a directory that have changed
+func (w *Watcher) dirChangedFiles(dir string) ([]string, error) {
+	var changed []string
 
 	// Walk through directory
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -132,9 +138,10 @@ func (w *Watcher) dirHasChanged(dir string) (bool, error) {
 			return nil
 		}
 
-		// Check if file has changed
+		// Record changed entries; a changed directory means a file
+		// was added or removed
 		if info.ModTime().After(w.lastBuild) {
-			changed = true
+			changed = append(changed, path)
 		}
 
 		return nil
@@ -1,30 +1,139 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Config represents the site configuration
 type Config struct {
-	Title         string   `json:"title" yaml:"title"`
-	BaseURL       string   `json:"baseURL" yaml:"baseURL"`
-	Theme         string   `json:"theme" yaml:"theme"`
-	Language      string   `json:"language" yaml:"language"`
-	ContentDir    string   `json:"contentDir" yaml:"contentDir"`
-	LayoutDir     string   `json:"layoutDir" yaml:"layoutDir"`
-	StaticDir     string   `json:"staticDir" yaml:"staticDir"`
-	OutputDir     string   `json:"outputDir" yaml:"outputDir"`
-	Author        string   `json:"author" yaml:"author"`
-	Description   string   `json:"description" yaml:"description"`
-	SummaryLength int      `json:"summaryLength" yaml:"summaryLength"`
-	Tags          []string `json:"tags" yaml:"tags"`
-	TrailingSlash bool     `json:"trailingSlash" yaml:"trailingSlash"`
+	Title         string      `json:"title" yaml:"title"`
+	BaseURL       string      `json:"baseURL" yaml:"baseURL"`
+	Theme         string      `json:"theme" yaml:"theme"`
+	Language      string      `json:"language" yaml:"language"`
+	ContentDir    string      `json:"contentDir" yaml:"contentDir"`
+	LayoutDir     string      `json:"layoutDir" yaml:"layoutDir"`
+	StaticDir     string      `json:"staticDir" yaml:"staticDir"`
+	OutputDir     string      `json:"outputDir" yaml:"outputDir"`
+	Author        string      `json:"author" yaml:"author"`
+	Description   string      `json:"description" yaml:"description"`
+	SummaryLength int         `json:"summaryLength" yaml:"summaryLength"`
+	Tags          []string    `json:"tags" yaml:"tags"`
+	TrailingSlash bool        `json:"trailingSlash" yaml:"trailingSlash"`
+	Hooks         Hooks       `json:"hooks" yaml:"hooks,omitempty"`
+	Minify        bool        `json:"minify" yaml:"minify,omitempty"`
+	CSSBundles    []CSSBundle `json:"cssBundles,omitempty" yaml:"cssBundles,omitempty"`
+	Imaging       Imaging     `json:"imaging" yaml:"imaging,omitempty"`
+	Redirects     []string    `json:"redirects,omitempty" yaml:"redirects,omitempty"`
+	// Permalinks maps a section (top-level content directory) to a URL
+	// pattern such as "/blog/:year/:month/:slug/"
+	Permalinks map[string]string `json:"permalinks,omitempty" yaml:"permalinks,omitempty"`
+	// Menus are navigation menus by name, e.g. "main"
+	Menus map[string][]MenuEntry `json:"menus,omitempty" yaml:"menus,omitempty"`
+	// SearchIndex configures the JSON search index
+	SearchIndex SearchIndex `json:"searchIndex" yaml:"searchIndex,omitempty"`
+	// Related configures how .Site.RegularPages.Related scores pages
+	Related Related `json:"related" yaml:"related,omitempty"`
+	// EnableGitInfo uses the last commit of each content file as the
+	// page's Lastmod when front matter doesn't set one
+	EnableGitInfo bool `json:"enableGitInfo,omitempty" yaml:"enableGitInfo,omitempty"`
+	// EnableRobotsTXT generates robots.txt from the robots.txt layout
+	EnableRobotsTXT bool `json:"enableRobotsTXT,omitempty" yaml:"enableRobotsTXT,omitempty"`
+	// Params holds settings for themes and templates, available as
+	// .Site.Params. A theme's config file can set defaults.
+	Params map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	// Languages makes the site multilingual, with the settings of each
+	// language by code. The language set by Language is published at the
+	// site root, the others below /<code>/ unless they set a baseURL.
+	Languages map[string]Language `json:"languages,omitempty" yaml:"languages,omitempty"`
+	// Environment is the name of the environment the site is built for,
+	// such as "production", "development" or "staging", which selects the
+	// config/<environment>/ overlay. It is set by the command being run
+	// rather than read from the config file.
+	Environment string `json:"-" yaml:"-"`
+
+	// sources maps the keys set by config files and environment variables
+	// to where they were set, e.g. "config.yml:3"
+	sources map[string]string
+	// warnings are problems found while loading that don't stop the build
+	warnings []string
+	// siteBaseURL is the baseURL of the site, for the config of a language
+	// published below it; static files are served from there
+	siteBaseURL string
+	// defaultLanguage is the site's language, for the config of a language
+	defaultLanguage string
+}
+
+// Imaging holds defaults for image processing in templates
+type Imaging struct {
+	// Quality is the JPEG quality (1-100) used when a spec doesn't set one
+	Quality int `json:"quality,omitempty" yaml:"quality,omitempty"`
+	// Anchor is the default crop anchor for Fill, e.g. "center" or "top"
+	Anchor string `json:"anchor,omitempty" yaml:"anchor,omitempty"`
+	// Widths are the srcset widths generated for Markdown images
+	Widths []int `json:"widths,omitempty" yaml:"widths,omitempty"`
+	// Sizes overrides the sizes attribute of Markdown images
+	Sizes string `json:"sizes,omitempty" yaml:"sizes,omitempty"`
+}
+
+// MenuEntry is a menu link defined in the config
+type MenuEntry struct {
+	// Identifier names the entry for Parent references (default: Name)
+	Identifier string `json:"identifier,omitempty" yaml:"identifier,omitempty"`
+	Name       string `json:"name" yaml:"name"`
+	// URL is a site path such as "/about/" or an absolute URL
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// PageRef is a content file whose URL the entry links to, e.g. "about.md"
+	PageRef string `json:"pageRef,omitempty" yaml:"pageRef,omitempty"`
+	Weight  int    `json:"weight,omitempty" yaml:"weight,omitempty"`
+	// Parent is the identifier of the entry this one is nested under
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+}
+
+// SearchIndex configures the search.json index used for client-side search
+type SearchIndex struct {
+	// Enable writes search.json to the output directory
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Fields are the page fields included in each entry: title, url,
+	// summary, tags, section, date and content
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// ShardBySection writes one index per section under search/, listed
+	// in search.json
+	ShardBySection bool `json:"shardBySection,omitempty" yaml:"shardBySection,omitempty"`
+}
+
+// Related configures the related pages of each page. Pages are scored by
+// what they share, and the score of pages published close together is
+// raised.
+type Related struct {
+	// Weights score what two pages share: "tags" counts for each shared
+	// tag and "section" for being in the same section
+	Weights map[string]float64 `json:"weights,omitempty" yaml:"weights,omitempty"`
+	// DateWeight raises the score of pages published on the same day by
+	// this fraction, less the further apart they are, down to nothing at
+	// DateRange days. 0 ignores dates.
+	DateWeight float64 `json:"dateWeight" yaml:"dateWeight"`
+	// DateRange is the number of days over which dates count
+	DateRange int `json:"dateRange,omitempty" yaml:"dateRange,omitempty"`
+	// Limit is the most related pages listed for a page
+	Limit int `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+// CSSBundle describes a stylesheet built by inlining the @import chain of
+// an entry file found in the static or assets directories
+type CSSBundle struct {
+	Entry     string `json:"entry" yaml:"entry"`
+	Output    string `json:"output,omitempty" yaml:"output,omitempty"`
+	SourceMap bool   `json:"sourceMap,omitempty" yaml:"sourceMap,omitempty"`
+}
+
+// Hooks lists external commands run around each build
+type Hooks struct {
+	PreBuild  []string `json:"preBuild,omitempty" yaml:"preBuild,omitempty"`
+	PostBuild []string `json:"postBuild,omitempty" yaml:"postBuild,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -43,7 +152,61 @@ func DefaultConfig() Config {
 		SummaryLength: 70,
 		Tags:          []string{},
 		TrailingSlash: true, // Default to trailing slashes for backward compatibility
+		Environment:   "production",
+		Related: Related{
+			Weights:    map[string]float64{"tags": 1},
+			DateWeight: 0.5,
+			DateRange:  365,
+			Limit:      5,
+		},
+	}
+}
+
+// IsProduction reports whether the site is built for production
+func (c Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// BasePath returns the path component of BaseURL with leading and trailing
+// slashes, e.g. "/docs/" for https://example.com/docs, or "/"
+func (c Config) BasePath() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "/"
 	}
+	basePath := strings.Trim(u.Path, "/")
+	if basePath == "" {
+		return "/"
+	}
+	return "/" + basePath + "/"
+}
+
+// RelURL returns the root-relative URL of a site path, including the
+// base path. Absolute URLs are returned unchanged.
+func (c Config) RelURL(p string) string {
+	basePath := c.BasePath()
+	if isAbsoluteURL(p) || (basePath != "/" && strings.HasPrefix(p, basePath)) {
+		return p
+	}
+	return basePath + strings.TrimPrefix(p, "/")
+}
+
+// AbsURL returns the absolute URL of a site path under BaseURL. Absolute
+// URLs are returned unchanged.
+func (c Config) AbsURL(p string) string {
+	if isAbsoluteURL(p) {
+		return p
+	}
+	baseURL := c.BaseURL
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + strings.TrimPrefix(p, "/")
+}
+
+// isAbsoluteURL reports whether p has a scheme or is protocol-relative
+func isAbsoluteURL(p string) bool {
+	return strings.HasPrefix(p, "//") || strings.Contains(p, "://")
 }
 
 // determineConfigType determines the file type (YAML or JSON) based on extension
@@ -60,21 +223,15 @@ func determineConfigType(path string) string {
 	}
 }
 
+// configFileNames are the names of config files in the order they are
+// looked for: YAML first (preferred), then JSON for backward compatibility
+var configFileNames = []string{"config.yml", "config.jsonc", "config.json"}
+
 // findConfigFile tries to find a configuration file in the site path
 func findConfigFile(sitePath string) (string, error) {
-	// Try YAML files first (preferred)
-	yamlPaths := []string{
-		filepath.Join(sitePath, "config.yml"),
-	}
-
-	// Then try JSON files (for backward compatibility)
-	jsonPaths := []string{
-		filepath.Join(sitePath, "config.jsonc"),
-		filepath.Join(sitePath, "config.json"),
-	}
-
 	// Check for files in preferred order
-	for _, path := range append(yamlPaths, jsonPaths...) {
+	for _, name := range configFileNames {
+		path := filepath.Join(sitePath, name)
 		if _, err := os.Stat(path); err == nil {
 			return path, nil
 		}
@@ -84,59 +241,127 @@ func findConfigFile(sitePath string) (string, error) {
 	return filepath.Join(sitePath, "config.yml"), nil
 }
 
-// LoadConfig loads the site configuration from a file
+// LoadConfig loads the site configuration for the environment named by
+// SCRIBE_ENV, or for production
 func LoadConfig(sitePath string) (Config, error) {
+	environment := os.Getenv(envPrefix + "ENV")
+	if environment == "" {
+		environment = "production"
+	}
+	return LoadConfigForEnvironment(sitePath, environment)
+}
+
+// LoadConfigForEnvironment loads the site configuration. Later sources
+// override earlier ones: the config file in the site root,
+// config/_default/, config/<environment>/ and SCRIBE_ environment
+// variables.
+func LoadConfigForEnvironment(sitePath, environment string) (Config, error) {
 	config := DefaultConfig()
 
-	configPath, err := findConfigFile(sitePath)
-	if err != nil {
+	found := false
+	for _, dir := range configDirs(sitePath, environment) {
+		configPath, err := findConfigFile(dir)
+		if err != nil {
+			return config, err
+		}
+
+		data, err := os.ReadFile(configPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return config, err
+		}
+		found = true
+
+		// Messages name files relative to the site
+		name := configPath
+		if rel, err := filepath.Rel(sitePath, configPath); err == nil {
+			name = rel
+		}
+
+		err = applyLayer(&config, func(c *Config) error {
+			return decodeConfig(name, data, c)
+		})
+		if err != nil {
+			return config, fmt.Errorf("error reading %s: %w", name, err)
+		}
+	}
+	if !found {
+		return config, fmt.Errorf("no config file in %s: %w", sitePath, os.ErrNotExist)
+	}
+
+	if err := applyEnvOverrides(&config, os.Environ()); err != nil {
 		return config, err
 	}
+	config.Environment = environment
 
-	data, err := os.ReadFile(configPath)
+	// The site's params override the defaults declared by its theme
+	themeConfig, err := loadThemeConfig(sitePath, config.Theme)
 	if err != nil {
 		return config, err
 	}
+	config.Params = mergeParams(themeConfig.Params, config.Params)
+	for key, source := range themeConfig.sources {
+		if strings.HasPrefix(key, "params.") && config.Source(key) == "" {
+			config.SetSource(key, source)
+		}
+	}
 
-	// Determine format based on file extension
-	configType := determineConfigType(configPath)
-	switch configType {
-	case "yaml":
-		err = yaml.Unmarshal(data, &config)
-	case "json":
-		err = json.Unmarshal(data, &config)
-	default:
-		return config, errors.New("unsupported configuration format")
+	if err := config.Validate(); err != nil {
+		return config, err
 	}
 
-	return config, err
+	return config, nil
 }
 
-// Save writes the configuration to a file
-func (c Config) Save(sitePath string) error {
-	// Get existing config path or use default
-	configPath, err := findConfigFile(sitePath)
-	if err != nil {
-		// Use default config path with YAML format
-		configPath = filepath.Join(sitePath, "config.yml")
+// loadThemeConfig returns the config file of a theme, if it has one. Only
+// its params are used.
+func loadThemeConfig(sitePath, theme string) (Config, error) {
+	var themeConfig Config
+	if theme == "" {
+		return themeConfig, nil
 	}
 
-	// Determine format based on file extension
-	configType := determineConfigType(configPath)
+	configPath, err := findConfigFile(filepath.Join(sitePath, "themes", theme))
+	if err != nil {
+		return themeConfig, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return themeConfig, nil
+	}
+	if err != nil {
+		return themeConfig, err
+	}
 
-	var data []byte
-	switch configType {
-	case "yaml":
-		data, err = yaml.Marshal(c)
-	case "json":
-		data, err = json.MarshalIndent(c, "", "  ")
-	default:
-		return errors.New("unsupported configuration format")
+	// Sources name the file relative to the site, like the site's own
+	name := filepath.Join("themes", theme, filepath.Base(configPath))
+	if err := decodeConfig(name, data, &themeConfig); err != nil {
+		return themeConfig, fmt.Errorf("error reading theme config %s: %w", configPath, err)
 	}
+	return themeConfig, nil
+}
 
-	if err != nil {
-		return err
+// mergeParams returns params with the defaults it doesn't set. Maps in
+// both are merged the same way.
+func mergeParams(defaults, params map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return params
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	merged := make(map[string]interface{}, len(defaults)+len(params))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range params {
+		defaultMap, ok1 := merged[key].(map[string]interface{})
+		paramMap, ok2 := value.(map[string]interface{})
+		if ok1 && ok2 {
+			merged[key] = mergeParams(defaultMap, paramMap)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
 }
@@ -262,3 +262,99 @@ func TestSaveAndLoadJSONConfig(t *testing.T) {
 		t.Errorf("Loaded TrailingSlash %t doesn't match original %t", loadedCfg.TrailingSlash, testCfg.TrailingSlash)
 	}
 }
+
+func TestURLHelpers(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		path     string
+		basePath string
+		relURL   string
+		absURL   string
+	}{
+		{"https://example.com/", "css/style.css", "/", "/css/style.css", "https://example.com/css/style.css"},
+		{"https://example.com/docs/", "/css/style.css", "/docs/", "/docs/css/style.css", "https://example.com/docs/css/style.css"},
+		{"https://example.com/docs", "about/", "/docs/", "/docs/about/", "https://example.com/docs/about/"},
+		{"https://example.com/docs/", "/docs/about/", "/docs/", "/docs/about/", "https://example.com/docs/docs/about/"},
+		{"https://example.com/docs/", "https://cdn.example.com/x.js", "/docs/", "https://cdn.example.com/x.js", "https://cdn.example.com/x.js"},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{BaseURL: tt.baseURL}
+		if got := cfg.BasePath(); got != tt.basePath {
+			t.Errorf("BasePath() for %s: Expected %q, got %q", tt.baseURL, tt.basePath, got)
+		}
+		if got := cfg.RelURL(tt.path); got != tt.relURL {
+			t.Errorf("RelURL(%q) for %s: Expected %q, got %q", tt.path, tt.baseURL, tt.relURL, got)
+		}
+		if got := cfg.AbsURL(tt.path); got != tt.absURL {
+			t.Errorf("AbsURL(%q) for %s: Expected %q, got %q", tt.path, tt.baseURL, tt.absURL, got)
+		}
+	}
+}
+
+func TestLoadConfigParams(t *testing.T) {
+	tempDir := t.TempDir()
+
+	siteConfig := `theme: starter
+params:
+  twitterHandle: scribe
+  hero:
+    title: Welcome
+  tags: [a, b]
+`
+	themeConfig := `title: Ignored
+params:
+  twitterHandle: theme
+  googleAnalytics: G-123
+  hero:
+    title: Theme hero
+    image: /images/hero.jpg
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yml"), []byte(siteConfig), 0644); err != nil {
+		t.Fatalf("Failed to write site config: %v", err)
+	}
+	themeDir := filepath.Join(tempDir, "themes", "starter")
+	if err := os.MkdirAll(themeDir, 0755); err != nil {
+		t.Fatalf("Failed to create theme directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(themeDir, "config.yml"), []byte(themeConfig), 0644); err != nil {
+		t.Fatalf("Failed to write theme config: %v", err)
+	}
+
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.Title != "Scribe" {
+		t.Errorf("Expected theme config to only provide params, got title %q", cfg.Title)
+	}
+	if got := cfg.Params["twitterHandle"]; got != "scribe" {
+		t.Errorf("Expected site param to override the theme, got %v", got)
+	}
+	if got := cfg.Params["googleAnalytics"]; got != "G-123" {
+		t.Errorf("Expected theme default for googleAnalytics, got %v", got)
+	}
+	hero, ok := cfg.Params["hero"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected hero to be a map, got %T", cfg.Params["hero"])
+	}
+	if hero["title"] != "Welcome" || hero["image"] != "/images/hero.jpg" {
+		t.Errorf("Expected nested params to be merged, got %v", hero)
+	}
+	if tags, ok := cfg.Params["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Expected tags list to be kept, got %v", cfg.Params["tags"])
+	}
+
+	// A site without a theme config keeps its own params
+	if err := os.RemoveAll(themeDir); err != nil {
+		t.Fatalf("Failed to remove theme: %v", err)
+	}
+	cfg, err = LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if len(cfg.Params) != 3 {
+		t.Errorf("Expected 3 params, got %v", cfg.Params)
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfig decodes a config file over config. Keys Config doesn't
+// have are reported as warnings with their line, and the location of
+// every key is recorded for Source. name is the file name used in
+// messages; its extension selects the format.
+func decodeConfig(name string, data []byte, config *Config) error {
+	var node *yaml.Node
+	switch determineConfigType(name) {
+	case "yaml":
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		if len(doc.Content) > 0 {
+			node = doc.Content[0]
+		}
+	default:
+		// JSON with comments and trailing commas is accepted in any JSON
+		// config file, not just config.jsonc
+		var err error
+		if node, err = jsonNode(stripJSONC(data)); err != nil {
+			return err
+		}
+	}
+
+	// An empty file sets nothing
+	if node == nil || node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a map of settings", node.Line)
+	}
+
+	if err := node.Decode(config); err != nil {
+		return err
+	}
+	config.inspectKeys(node, reflect.TypeOf(*config), "", name, true)
+	return nil
+}
+
+// inspectKeys walks a mapping node decoded into type t. It warns about
+// keys t doesn't have and, when record is set, records where each key was
+// set. Lists are walked for warnings only.
+func (c *Config) inspectKeys(node *yaml.Node, t reflect.Type, prefix, name string, record bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		var valueType reflect.Type
+		switch t.Kind() {
+		case reflect.Struct:
+			names := fieldNames(t)
+			field, ok := structField(t, key.Value)
+			if !ok {
+				message := fmt.Sprintf("%s:%d: unknown key %q", name, key.Line, path)
+				if suggestion := suggest(key.Value, names); suggestion != "" {
+					message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				c.warnings = append(c.warnings, message)
+				continue
+			}
+			valueType = field.Type
+		case reflect.Map:
+			valueType = t.Elem()
+		default:
+			continue
+		}
+
+		if record {
+			c.SetSource(path, fmt.Sprintf("%s:%d", name, key.Line))
+		}
+
+		// Maps of any value, such as params, nest further maps
+		if valueType.Kind() == reflect.Interface {
+			valueType = reflect.TypeOf(map[string]interface{}{})
+		}
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			c.inspectKeys(value, valueType, path, name, record)
+		case yaml.SequenceNode:
+			if valueType.Kind() != reflect.Slice {
+				continue
+			}
+			for j, item := range value.Content {
+				if item.Kind == yaml.MappingNode {
+					c.inspectKeys(item, valueType.Elem(), fmt.Sprintf("%s[%d]", path, j), name, false)
+				}
+			}
+		}
+	}
+}
+
+// fieldNames returns the config keys of a struct type
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// structField returns the field of t with the config key name
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// yamlName returns the config key of a struct field, or "" for fields that
+// aren't read from config files
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// suggest returns the name in known closest to name, or "" when none is
+// close enough to be a likely typo
+func suggest(name string, known []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range known {
+		if strings.EqualFold(candidate, name) {
+			return candidate
+		}
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if bestDistance >= 0 && bestDistance <= max(1, len(name)/3) {
+		return best
+	}
+	return ""
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters that turn a into b
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// SetSource records where a key was set, e.g. "--minify" for a setting
+// changed by a command line flag
+func (c *Config) SetSource(key, source string) {
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+	c.sources[key] = source
+}
+
+// setSources records where a key and the keys of maps in its value were set
+func (c *Config) setSources(key string, value *yaml.Node, source string) {
+	c.SetSource(key, source)
+	if value.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		c.setSources(key+"."+value.Content[i].Value, value.Content[i+1], source)
+	}
+}
+
+// Source returns where a key such as "baseURL" or "params.hero.title" was
+// set, e.g. "config.yml:3" or "SCRIBE_BASEURL", or "" for defaults
+func (c Config) Source(key string) string {
+	return c.sources[key]
+}
+
+// Warnings returns problems found while loading the config that don't
+// stop the build, such as unknown keys
+func (c Config) Warnings() []string {
+	return c.warnings
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigWarnings(t *testing.T) {
+	tempDir := t.TempDir()
+	config := `title: My Site
+baseUrl: https://example.com/
+outputdir: out
+hooks:
+  prebuild: [make]
+menus:
+  main:
+    - name: Home
+      link: /
+params:
+  anything: goes
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	want := []string{
+		`config.yml:2: unknown key "baseUrl" (did you mean "baseURL"?)`,
+		`config.yml:3: unknown key "outputdir" (did you mean "outputDir"?)`,
+		`config.yml:5: unknown key "hooks.prebuild" (did you mean "preBuild"?)`,
+		`config.yml:9: unknown key "menus.main[0].link"`,
+	}
+	if !reflect.DeepEqual(cfg.Warnings(), want) {
+		t.Errorf("Expected warnings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(cfg.Warnings(), "\n"))
+	}
+
+	// Unknown keys are ignored rather than applied case-insensitively
+	if cfg.BaseURL != DefaultConfig().BaseURL || cfg.OutputDir != "public" {
+		t.Errorf("Expected misspelled keys to be ignored, got baseURL %q and outputDir %q", cfg.BaseURL, cfg.OutputDir)
+	}
+
+	if got := cfg.Source("title"); got != "config.yml:1" {
+		t.Errorf("Expected title to come from config.yml:1, got %q", got)
+	}
+	if got := cfg.Source("params.anything"); got != "config.yml:11" {
+		t.Errorf("Expected params.anything to come from config.yml:11, got %q", got)
+	}
+	if got := cfg.Source("summaryLength"); got != "" {
+		t.Errorf("Expected no source for a default, got %q", got)
+	}
+}
+
+func TestLoadConfigJSONC(t *testing.T) {
+	tempDir := t.TempDir()
+	config := `{
+  // The site title
+  "title": "JSONC Site", /* block comment */
+  "baseURL": "https://example.com/", // "not a key": true
+  "description": "Slashes // and /* stars */ in strings",
+  "summaryLength": 30,
+  "tags": [
+    "go",
+    "web", // trailing comma
+  ],
+  "baseUrl": "https://typo.example.com/",
+}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "config.jsonc"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.Title != "JSONC Site" || cfg.BaseURL != "https://example.com/" || cfg.SummaryLength != 30 {
+		t.Errorf("Unexpected config: title %q, baseURL %q, summaryLength %d", cfg.Title, cfg.BaseURL, cfg.SummaryLength)
+	}
+	if cfg.Description != "Slashes // and /* stars */ in strings" {
+		t.Errorf("Expected comment markers in strings to be kept, got %q", cfg.Description)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"go", "web"}) {
+		t.Errorf("Expected tags [go web], got %v", cfg.Tags)
+	}
+
+	want := []string{`config.jsonc:11: unknown key "baseUrl" (did you mean "baseURL"?)`}
+	if !reflect.DeepEqual(cfg.Warnings(), want) {
+		t.Errorf("Expected warnings %v, got %v", want, cfg.Warnings())
+	}
+}
+
+func TestLoadConfigSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		want string
+	}{
+		{"config.jsonc", "{\n  \"title\": \"x\"\n  \"baseURL\": \"y\"\n}", "line 3"},
+		{"config.json", "{\n  \"summaryLength\": \"many\"\n}", "line 2"},
+		{"config.yml", "title: x\nsummaryLength: many\n", "line 2"},
+		{"config.yml", "- a\n- b\n", "expected a map of settings"},
+	}
+
+	for _, tt := range tests {
+		tempDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tempDir, tt.file), []byte(tt.data), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		_, err := LoadConfig(tempDir)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.file) {
+			t.Errorf("%s: Expected error mentioning %s and %q, got %v", tt.data, tt.file, tt.want, err)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	known := []string{"baseURL", "outputDir", "contentDir", "title"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"baseurl", "baseURL"},
+		{"outptDir", "outputDir"},
+		{"titel", "title"},
+		{"somethingElse", ""},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.name, known); got != tt.want {
+			t.Errorf("suggest(%q): Expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the names of environment variables read by Scribe:
+// SCRIBE_ENV selects the environment, and others override config keys,
+// e.g. SCRIBE_BASEURL or SCRIBE_SEARCHINDEX_ENABLE
+const envPrefix = "SCRIBE_"
+
+// configDirs returns the directories holding config files for an
+// environment, in order of precedence from lowest to highest
+func configDirs(sitePath, environment string) []string {
+	dirs := []string{
+		sitePath,
+		filepath.Join(sitePath, "config", "_default"),
+	}
+	if environment != "" && environment != "_default" {
+		dirs = append(dirs, filepath.Join(sitePath, "config", environment))
+	}
+	return dirs
+}
+
+// IsConfigFile reports whether path is a file the config of the site at
+// sitePath may be loaded from: a config file in the site root or a theme,
+// or anything below config/
+func IsConfigFile(sitePath, path string) bool {
+	rel, err := filepath.Rel(sitePath, path)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if parts[0] == "config" && len(parts) > 1 {
+		return true
+	}
+	if len(parts) != 1 && (len(parts) != 3 || parts[0] != "themes") {
+		return false
+	}
+	for _, name := range configFileNames {
+		if parts[len(parts)-1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyLayer decodes a config source over config. Fields it doesn't set
+// are kept, maps such as permalinks and menus are merged by key, and
+// params are merged at every level.
+func applyLayer(config *Config, decode func(*Config) error) error {
+	params := config.Params
+	config.Params = nil
+	if err := decode(config); err != nil {
+		config.Params = params
+		return err
+	}
+	config.Params = mergeParams(params, config.Params)
+	return nil
+}
+
+// applyEnvOverrides sets the config keys named by SCRIBE_ variables in
+// environ, matching names case-insensitively and using _ between nested
+// keys. Values are read as YAML, so SCRIBE_TAGS="[a, b]" sets a list.
+// Variables that don't name a key, such as SCRIBE_ENV, are ignored.
+func applyEnvOverrides(config *Config, environ []string) error {
+	sorted := make([]string, len(environ))
+	copy(sorted, environ)
+	sort.Strings(sorted)
+
+	for _, entry := range sorted {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+
+		segments := strings.Split(strings.TrimPrefix(name, envPrefix), "_")
+		keys, ok := envKeys(reflect.ValueOf(*config), reflect.TypeOf(*config), segments)
+		if !ok {
+			continue
+		}
+
+		node := envValue(value)
+		for i := len(keys) - 1; i >= 0; i-- {
+			node = &yaml.Node{
+				Kind:    yaml.MappingNode,
+				Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: keys[i]}, node},
+			}
+		}
+
+		if err := applyLayer(config, func(c *Config) error { return node.Decode(c) }); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		config.setSources(strings.Join(keys, "."), envValue(value), name)
+	}
+	return nil
+}
+
+// envValue parses the value of an environment variable as YAML, falling
+// back to a plain string
+func envValue(value string) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err == nil && len(doc.Content) == 1 {
+		return doc.Content[0]
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// envKeys resolves the segments of an environment variable name to config
+// keys. v is the current value at this level, which may be invalid when
+// nothing is set. Map keys match existing keys, which may contain
+// underscores; new map keys are lowercased.
+func envKeys(v reflect.Value, t reflect.Type, segments []string) ([]string, bool) {
+	if len(segments) == 0 {
+		return nil, true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" || !strings.EqualFold(name, segments[0]) {
+				continue
+			}
+			var field reflect.Value
+			if v.IsValid() {
+				field = v.Field(i)
+			}
+			rest, ok := envKeys(field, t.Field(i).Type, segments[1:])
+			return append([]string{name}, rest...), ok
+		}
+		return nil, false
+
+	case reflect.Map:
+		if v.IsValid() {
+			// The longest existing key wins, e.g. google_analytics for
+			// SCRIBE_PARAMS_GOOGLE_ANALYTICS
+			for n := len(segments); n > 0; n-- {
+				joined := strings.Join(segments[:n], "_")
+				iter := v.MapRange()
+				for iter.Next() {
+					if strings.EqualFold(iter.Key().String(), joined) {
+						rest, ok := envKeys(iter.Value(), t.Elem(), segments[n:])
+						return append([]string{iter.Key().String()}, rest...), ok
+					}
+				}
+			}
+		}
+		rest, ok := envKeys(reflect.Value{}, t.Elem(), segments[1:])
+		return append([]string{strings.ToLower(segments[0])}, rest...), ok
+
+	case reflect.Interface:
+		if v.IsValid() && !v.IsNil() {
+			return envKeys(v.Elem(), v.Elem().Type(), segments)
+		}
+		// Nothing is set, so each segment is a new nested map
+		keys := make([]string, len(segments))
+		for i, segment := range segments {
+			keys[i] = strings.ToLower(segment)
+		}
+		return keys, true
+	}
+
+	// Scalars and lists have no keys below them
+	return nil, false
+}
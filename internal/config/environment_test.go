@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigForEnvironment(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config/_default/config.yml": `title: My Site
+baseURL: http://localhost/
+enableRobotsTXT: false
+permalinks:
+  posts: /:year/:slug/
+params:
+  analytics:
+    enabled: false
+    id: G-DEFAULT
+`,
+		"config/production/config.yml": `baseURL: https://example.com/
+enableRobotsTXT: true
+permalinks:
+  docs: /:sections/:slug/
+params:
+  analytics:
+    enabled: true
+`,
+		"config/staging/config.json": `{"baseURL": "https://staging.example.com/"}`,
+	}
+	for name, data := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := LoadConfigForEnvironment(tempDir, "production")
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Title != "My Site" || cfg.BaseURL != "https://example.com/" || !cfg.EnableRobotsTXT {
+		t.Errorf("Expected production overlay over the defaults, got title %q, baseURL %q, robots %v", cfg.Title, cfg.BaseURL, cfg.EnableRobotsTXT)
+	}
+	if !cfg.IsProduction() {
+		t.Errorf("Expected environment production, got %q", cfg.Environment)
+	}
+	wantPermalinks := map[string]string{"posts": "/:year/:slug/", "docs": "/:sections/:slug/"}
+	if !reflect.DeepEqual(cfg.Permalinks, wantPermalinks) {
+		t.Errorf("Expected permalinks %v, got %v", wantPermalinks, cfg.Permalinks)
+	}
+	wantAnalytics := map[string]interface{}{"enabled": true, "id": "G-DEFAULT"}
+	if !reflect.DeepEqual(cfg.Params["analytics"], wantAnalytics) {
+		t.Errorf("Expected analytics params %v, got %v", wantAnalytics, cfg.Params["analytics"])
+	}
+
+	cfg, err = LoadConfigForEnvironment(tempDir, "staging")
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.BaseURL != "https://staging.example.com/" || cfg.EnableRobotsTXT || cfg.Environment != "staging" {
+		t.Errorf("Expected staging overlay, got baseURL %q, robots %v, environment %q", cfg.BaseURL, cfg.EnableRobotsTXT, cfg.Environment)
+	}
+
+	// Environments without a directory use the defaults
+	cfg, err = LoadConfigForEnvironment(tempDir, "development")
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.BaseURL != "http://localhost/" || cfg.IsProduction() {
+		t.Errorf("Expected default config for development, got baseURL %q, environment %q", cfg.BaseURL, cfg.Environment)
+	}
+
+	// A site without any config file is an error
+	if _, err := LoadConfigForEnvironment(t.TempDir(), "production"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing config error, got %v", err)
+	}
+}
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	config := `title: My Site
+params:
+  google_analytics: G-FILE
+  hero:
+    title: Welcome
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Setenv("SCRIBE_ENV", "staging")
+	t.Setenv("SCRIBE_BASEURL", "https://staging.example.com/")
+	t.Setenv("SCRIBE_SUMMARYLENGTH", "20")
+	t.Setenv("SCRIBE_SEARCHINDEX_ENABLE", "true")
+	t.Setenv("SCRIBE_TAGS", "[go, web]")
+	t.Setenv("SCRIBE_PARAMS_GOOGLE_ANALYTICS", "G-ENV")
+	t.Setenv("SCRIBE_PARAMS_HERO_IMAGE", "/hero.jpg")
+	t.Setenv("SCRIBE_HOOK", "not a config key")
+
+	cfg, err := LoadConfig(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.Environment != "staging" {
+		t.Errorf("Expected SCRIBE_ENV to select staging, got %q", cfg.Environment)
+	}
+	if cfg.BaseURL != "https://staging.example.com/" {
+		t.Errorf("Expected SCRIBE_BASEURL to override baseURL, got %q", cfg.BaseURL)
+	}
+	if cfg.SummaryLength != 20 {
+		t.Errorf("Expected summaryLength 20, got %d", cfg.SummaryLength)
+	}
+	if !cfg.SearchIndex.Enable {
+		t.Errorf("Expected SCRIBE_SEARCHINDEX_ENABLE to enable the search index")
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"go", "web"}) {
+		t.Errorf("Expected tags [go web], got %v", cfg.Tags)
+	}
+	if cfg.Title != "My Site" {
+		t.Errorf("Expected title from the config file, got %q", cfg.Title)
+	}
+	if got := cfg.Params["google_analytics"]; got != "G-ENV" {
+		t.Errorf("Expected existing param with underscores to be overridden, got %v", got)
+	}
+	wantHero := map[string]interface{}{"title": "Welcome", "image": "/hero.jpg"}
+	if !reflect.DeepEqual(cfg.Params["hero"], wantHero) {
+		t.Errorf("Expected hero params %v, got %v", wantHero, cfg.Params["hero"])
+	}
+
+	t.Setenv("SCRIBE_SUMMARYLENGTH", "many")
+	if _, err := LoadConfig(tempDir); err == nil {
+		t.Errorf("Expected an invalid SCRIBE_SUMMARYLENGTH to fail")
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	site := filepath.Join("sites", "blog")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"config.yml", true},
+		{"config.jsonc", true},
+		{filepath.Join("config", "_default", "config.yml"), true},
+		{filepath.Join("config", "staging", "params.yml"), true},
+		{filepath.Join("themes", "basic", "config.yml"), true},
+		{filepath.Join("themes", "basic", "layouts", "config.yml"), false},
+		{filepath.Join("content", "config.yml"), false},
+		{"config", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := IsConfigFile(site, filepath.Join(site, tt.path)); got != tt.want {
+			t.Errorf("IsConfigFile(%q): expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stripJSONC turns JSON with comments and trailing commas into JSON.
+// Comments are replaced with spaces, so offsets and line numbers don't
+// change.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				out = append(out, ' ')
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := len(data)
+			if j := bytes.Index(data[i+2:], []byte("*/")); j >= 0 {
+				end = i + 2 + j + 2
+			}
+			for ; i < end; i++ {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+			i--
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.IndexByte(" \t\r\n", out[j]) >= 0 {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out[j] = ' '
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// jsonNode parses JSON into a YAML node, so JSON config files are checked
+// and decoded like YAML ones, with line numbers. It returns nil for an
+// empty document.
+func jsonNode(data []byte) (*yaml.Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	lineAt := func(offset int64) int {
+		if offset > int64(len(data)) {
+			offset = int64(len(data))
+		}
+		return 1 + bytes.Count(data[:offset], []byte("\n"))
+	}
+	wrap := func(err error) error {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("line %d: %w", lineAt(syntaxErr.Offset), err)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("line %d: unexpected end of JSON", lineAt(int64(len(data))))
+		}
+		return err
+	}
+
+	var parse func(token json.Token) (*yaml.Node, error)
+	parse = func(token json.Token) (*yaml.Node, error) {
+		line := lineAt(dec.InputOffset())
+		switch v := token.(type) {
+		case json.Delim:
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+			if v == '[' {
+				node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			}
+			for dec.More() {
+				if node.Kind == yaml.MappingNode {
+					key, err := dec.Token()
+					if err != nil {
+						return nil, wrap(err)
+					}
+					name, _ := key.(string)
+					node.Content = append(node.Content, &yaml.Node{
+						Kind:  yaml.ScalarNode,
+						Tag:   "!!str",
+						Value: name,
+						Line:  lineAt(dec.InputOffset()),
+					})
+				}
+
+				next, err := dec.Token()
+				if err != nil {
+					return nil, wrap(err)
+				}
+				value, err := parse(next)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, value)
+			}
+			// The closing bracket
+			if _, err := dec.Token(); err != nil {
+				return nil, wrap(err)
+			}
+			return node, nil
+		case string:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v, Line: line}, nil
+		case json.Number:
+			tag := "!!int"
+			if strings.ContainsAny(v.String(), ".eE") {
+				tag = "!!float"
+			}
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String(), Line: line}, nil
+		case bool:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v), Line: line}, nil
+		default:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null", Line: line}, nil
+		}
+	}
+
+	token, err := dec.Token()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrap(err)
+	}
+	node, err := parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("line %d: unexpected data after the JSON document", lineAt(dec.InputOffset()))
+	}
+	return node, nil
+}
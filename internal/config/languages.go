@@ -0,0 +1,108 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Language holds the settings of one language of a multilingual site.
+// Settings left empty are taken from the site.
+type Language struct {
+	// Code is the key of the language in languages, e.g. "de"
+	Code string `json:"-" yaml:"-"`
+	// LanguageName is the name shown in language switchers, e.g. "Deutsch"
+	LanguageName string `json:"languageName,omitempty" yaml:"languageName,omitempty"`
+	// Weight orders the languages in LanguageList
+	Weight      int    `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// BaseURL publishes the language at its own URL, such as a separate
+	// domain. Its pages are still written below <outputDir>/<code>/.
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+	// ContentDir holds the pages of the language. By default languages
+	// share the site's content directory, with translations named like
+	// post.de.md.
+	ContentDir string `json:"contentDir,omitempty" yaml:"contentDir,omitempty"`
+	// Params override the site's params
+	Params map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	// Menus replace the site's menus
+	Menus map[string][]MenuEntry `json:"menus,omitempty" yaml:"menus,omitempty"`
+}
+
+// IsMultilingual reports whether the site configures languages
+func (c Config) IsMultilingual() bool {
+	return len(c.Languages) > 0
+}
+
+// LanguageList returns the configured languages sorted by weight, then
+// code
+func (c Config) LanguageList() []Language {
+	list := make([]Language, 0, len(c.Languages))
+	for code, lang := range c.Languages {
+		lang.Code = code
+		list = append(list, lang)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Weight != list[j].Weight {
+			return list[i].Weight < list[j].Weight
+		}
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// DefaultLanguage returns the code of the language published at the site
+// root
+func (c Config) DefaultLanguage() string {
+	if c.defaultLanguage != "" {
+		return c.defaultLanguage
+	}
+	return c.Language
+}
+
+// ForLanguage returns the config a language of a multilingual site is
+// built with: the site's, with the language's settings on top. Languages
+// other than the default one are written to <outputDir>/<code>/ and, unless
+// they set a baseURL, published at <baseURL>/<code>/.
+func (c Config) ForLanguage(code string) Config {
+	lang := c.Languages[code]
+	lc := c
+	lc.Language = code
+	lc.defaultLanguage = c.Language
+
+	if lang.Title != "" {
+		lc.Title = lang.Title
+	}
+	if lang.Description != "" {
+		lc.Description = lang.Description
+	}
+	if lang.ContentDir != "" {
+		lc.ContentDir = lang.ContentDir
+	}
+	if lang.Menus != nil {
+		lc.Menus = lang.Menus
+	}
+	lc.Params = mergeParams(c.Params, lang.Params)
+
+	switch {
+	case lang.BaseURL != "":
+		lc.BaseURL = lang.BaseURL
+	case code != c.Language:
+		lc.BaseURL = c.AbsURL(code + "/")
+		lc.siteBaseURL = c.BaseURL
+	}
+	if code != c.Language {
+		lc.OutputDir = filepath.Join(c.OutputDir, code)
+	}
+	return lc
+}
+
+// Root returns the config that the URLs of static files are relative to:
+// the site's for a language published below it, otherwise c
+func (c Config) Root() Config {
+	if c.siteBaseURL != "" {
+		c.BaseURL = c.siteBaseURL
+		c.siteBaseURL = ""
+	}
+	return c
+}
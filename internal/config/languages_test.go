@@ -0,0 +1,71 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLanguageList(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Languages = map[string]Language{
+		"fr": {Weight: 2},
+		"de": {Weight: 2},
+		"en": {Weight: 1},
+	}
+
+	var codes []string
+	for _, lang := range cfg.LanguageList() {
+		codes = append(codes, lang.Code)
+	}
+	if len(codes) != 3 || codes[0] != "en" || codes[1] != "de" || codes[2] != "fr" {
+		t.Errorf("Expected languages [en de fr], got %v", codes)
+	}
+}
+
+func TestForLanguage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Title = "Blog"
+	cfg.BaseURL = "https://example.com/"
+	cfg.Params = map[string]interface{}{"author": "Ada", "tagline": "Hello"}
+	cfg.Languages = map[string]Language{
+		"en": {Weight: 1},
+		"de": {Weight: 2, Title: "Blog auf Deutsch", Params: map[string]interface{}{"tagline": "Hallo"}},
+		"fr": {Weight: 3, BaseURL: "https://example.fr/", ContentDir: "content/fr"},
+	}
+
+	en := cfg.ForLanguage("en")
+	if en.BaseURL != cfg.BaseURL || en.OutputDir != cfg.OutputDir || en.Title != "Blog" {
+		t.Errorf("Expected the default language to keep the site's settings, got %+v", en)
+	}
+
+	de := cfg.ForLanguage("de")
+	if de.Language != "de" || de.DefaultLanguage() != "en" {
+		t.Errorf("Expected language de with default en, got %q and %q", de.Language, de.DefaultLanguage())
+	}
+	if de.Title != "Blog auf Deutsch" {
+		t.Errorf("Expected the language title, got %q", de.Title)
+	}
+	if de.Params["tagline"] != "Hallo" || de.Params["author"] != "Ada" {
+		t.Errorf("Expected params to be merged, got %v", de.Params)
+	}
+	if de.BaseURL != "https://example.com/de/" {
+		t.Errorf("Expected baseURL https://example.com/de/, got %q", de.BaseURL)
+	}
+	if de.Root().BaseURL != "https://example.com/" {
+		t.Errorf("Expected the root baseURL https://example.com/, got %q", de.Root().BaseURL)
+	}
+	if want := filepath.Join("public", "de"); de.OutputDir != want {
+		t.Errorf("Expected outputDir %s, got %q", want, de.OutputDir)
+	}
+
+	fr := cfg.ForLanguage("fr")
+	if fr.BaseURL != "https://example.fr/" || fr.Root().BaseURL != "https://example.fr/" {
+		t.Errorf("Expected baseURL https://example.fr/, got %q", fr.BaseURL)
+	}
+	if fr.ContentDir != "content/fr" {
+		t.Errorf("Expected contentDir content/fr, got %q", fr.ContentDir)
+	}
+	if cfg.Params["tagline"] != "Hello" {
+		t.Errorf("Expected the site's params to be left alone, got %v", cfg.Params)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes the value of a key such as "outputDir" or "params.hero", or
+// the whole config when key is "", to w. The "yaml" and "json" formats
+// write plain values for scripts. Otherwise values are written as YAML
+// with a comment saying where each was set, such as "config.yml:4",
+// "SCRIBE_OUTPUTDIR" or "default", and a single value is written as is.
+func (c Config) Print(w io.Writer, key, format string) error {
+	switch format {
+	case "", "yaml", "json":
+	default:
+		return fmt.Errorf("unknown format %q, use yaml or json", format)
+	}
+
+	var root yaml.Node
+	if err := root.Encode(c); err != nil {
+		return err
+	}
+	node, err := c.lookup(&root, key)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch format {
+	case "json":
+		var buf bytes.Buffer
+		err = writeJSON(&buf, node, "")
+		buf.WriteByte('\n')
+		out = buf.Bytes()
+	case "yaml":
+		out, err = encodeYAML(node, 2)
+	default:
+		if node.Kind == yaml.ScalarNode {
+			out = []byte(node.Value + "\n")
+			break
+		}
+		c.annotate(node, key)
+		if key == "" && c.Environment != "" {
+			node = &yaml.Node{
+				Kind:        yaml.DocumentNode,
+				HeadComment: fmt.Sprintf("Effective configuration for the %s environment", c.Environment),
+				Content:     []*yaml.Node{node},
+			}
+		}
+		out, err = encodeYAML(node, 2)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// lookup returns the node of a dotted key in root, the encoded config
+func (c Config) lookup(root *yaml.Node, key string) (*yaml.Node, error) {
+	if key == "" {
+		return root, nil
+	}
+
+	segments := strings.Split(key, ".")
+	if err := checkKey(reflect.TypeOf(c), segments, ""); err != nil {
+		return nil, err
+	}
+	node := root
+	for _, segment := range segments {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%q is not set", key)
+		}
+		if node = mappingValue(node, segment); node == nil {
+			return nil, fmt.Errorf("%q is not set", key)
+		}
+	}
+	return node, nil
+}
+
+// annotate adds the source of each value in a mapping node as a comment.
+// Maps are annotated key by key; lists as a whole.
+func (c Config) annotate(node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		source := c.Source(path)
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+			// Keys within the map carry their own sources
+			if source != "" {
+				key.LineComment = source
+			}
+			c.annotate(value, path)
+			continue
+		}
+
+		if source == "" {
+			source = "default"
+		}
+		if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+			value.LineComment = source
+		} else {
+			key.LineComment = source
+		}
+	}
+}
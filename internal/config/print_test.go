@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrintAnnotatesSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yml":                "title: Blog\nparams:\n  hero:\n    title: Hi\n",
+		"config/staging/config.yml": "outputDir: dist\n",
+		"themes/default/config.yml": "params:\n  hero:\n    image: hero.png\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("SCRIBE_SUMMARYLENGTH", "20")
+
+	cfg, err := LoadConfigForEnvironment(dir, "staging")
+	if err != nil {
+		t.Fatalf("LoadConfigForEnvironment failed: %v", err)
+	}
+	cfg.Minify = true
+	cfg.SetSource("minify", "--minify")
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf, "", ""); err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Effective configuration for the staging environment\n",
+		"title: Blog # config.yml:1\n",
+		"outputDir: dist # config/staging/config.yml:1\n",
+		"summaryLength: 20 # SCRIBE_SUMMARYLENGTH\n",
+		"minify: true # --minify\n",
+		"theme: default # default\n",
+		"params: # config.yml:2\n",
+		"    image: hero.png # themes/default/config.yml:3\n",
+		"    title: Hi # config.yml:4\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Params = map[string]interface{}{"hero": map[string]interface{}{"title": "Hi"}}
+	cfg.SetSource("params.hero.title", "config.yml:3")
+
+	tests := []struct {
+		key    string
+		format string
+		want   string
+	}{
+		{"outputDir", "", "public\n"},
+		{"outputDir", "json", "\"public\"\n"},
+		{"summaryLength", "yaml", "70\n"},
+		{"params", "", "hero:\n  title: Hi # config.yml:3\n"},
+		{"params", "json", "{\n  \"hero\": {\n    \"title\": \"Hi\"\n  }\n}\n"},
+		{"tags", "json", "[]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := cfg.Print(&buf, tt.key, tt.format); err != nil {
+			t.Errorf("Print(%s, %q) failed: %v", tt.key, tt.format, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Print(%s, %q): expected %q, got %q", tt.key, tt.format, tt.want, buf.String())
+		}
+	}
+
+	failures := []struct {
+		key    string
+		format string
+		want   string
+	}{
+		{"outptDir", "", `did you mean "outputDir"?`},
+		{"params.footer", "", `"params.footer" is not set`},
+		{"title", "toml", `unknown format "toml"`},
+	}
+	for _, tt := range failures {
+		err := cfg.Print(&bytes.Buffer{}, tt.key, tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q for %s, got %v", tt.want, tt.key, err)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfigFile loads only the config file in the site root, without
+// config/ overlays, SCRIBE_ variables or theme params. It's the config
+// Save writes back.
+func LoadConfigFile(sitePath string) (Config, error) {
+	config := DefaultConfig()
+
+	configPath, err := findConfigFile(sitePath)
+	if err != nil {
+		return config, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return config, fmt.Errorf("no config file in %s: %w", sitePath, os.ErrNotExist)
+	}
+	if err != nil {
+		return config, err
+	}
+
+	name := filepath.Base(configPath)
+	if err := decodeConfig(name, data, &config); err != nil {
+		return config, fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return config, nil
+}
+
+// Save writes the configuration to the config file in the site root,
+// creating config.yml if there is none. Only the keys whose values differ
+// from the file's are changed, so comments, key order and formatting are
+// kept and settings left at their defaults aren't written out. Comments
+// are only kept in YAML files.
+func (c Config) Save(sitePath string) error {
+	configPath, err := findConfigFile(sitePath)
+	if err != nil {
+		configPath = filepath.Join(sitePath, "config.yml")
+	}
+	configType := determineConfigType(configPath)
+
+	data, err := os.ReadFile(configPath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// The document to edit and the settings it holds now
+	doc, err := parseConfigDocument(configType, data)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", configPath, err)
+	}
+	root := doc.Content[0]
+	saved := DefaultConfig()
+	if err := root.Decode(&saved); err != nil {
+		return fmt.Errorf("error reading %s: %w", configPath, err)
+	}
+
+	var current, previous yaml.Node
+	if err := current.Encode(c); err != nil {
+		return err
+	}
+	if err := previous.Encode(saved); err != nil {
+		return err
+	}
+	if !updateMapping(root, &current, &previous) && exists {
+		return nil
+	}
+
+	var out []byte
+	if configType == "yaml" {
+		out, err = encodeYAML(doc, detectIndent(data))
+	} else {
+		var buf bytes.Buffer
+		err = writeJSON(&buf, root, "")
+		buf.WriteByte('\n')
+		out = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(configPath, out, 0644)
+}
+
+// Set sets a key such as "title" or "params.hero.title" to value, which is
+// read as YAML like SCRIBE_ variables are, so "[a, b]" sets a list
+func (c *Config) Set(key, value string) error {
+	segments := strings.Split(key, ".")
+	if err := checkKey(reflect.TypeOf(*c), segments, ""); err != nil {
+		return err
+	}
+
+	node := envValue(value)
+	for i := len(segments) - 1; i >= 0; i-- {
+		node = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Tag:     "!!map",
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: segments[i]}, node},
+		}
+	}
+
+	err := applyLayer(c, func(config *Config) error {
+		return node.Decode(config)
+	})
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// checkKey reports an error when the key segments don't name a setting of
+// type t. Keys below maps, such as params, can be anything.
+func checkKey(t reflect.Type, segments []string, prefix string) error {
+	if len(segments) == 0 {
+		return nil
+	}
+	path := segments[0]
+	if prefix != "" {
+		path = prefix + "." + segments[0]
+	}
+	if segments[0] == "" {
+		return fmt.Errorf("invalid key %q", path)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := structField(t, segments[0])
+		if !ok {
+			message := fmt.Sprintf("unknown key %q", path)
+			if suggestion := suggest(segments[0], fieldNames(t)); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			return fmt.Errorf("%s", message)
+		}
+		return checkKey(field.Type, segments[1:], path)
+	case reflect.Map:
+		return checkKey(t.Elem(), segments[1:], path)
+	case reflect.Interface:
+		return nil
+	}
+	return fmt.Errorf("%q is not a map of settings", prefix)
+}
+
+// parseConfigDocument parses a config file into a document node holding a
+// mapping. Empty files give an empty mapping.
+func parseConfigDocument(configType string, data []byte) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	if configType == "yaml" {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, err
+		}
+		doc.Kind = yaml.DocumentNode
+	} else {
+		root, err := jsonNode(stripJSONC(data))
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			doc.Content = []*yaml.Node{root}
+		}
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if root := doc.Content[0]; root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a map of settings", root.Line)
+	}
+	return doc, nil
+}
+
+// updateMapping changes file, a mapping from a config file, where current
+// differs from previous, the same settings before they were changed. Keys
+// that didn't change are left alone, nested maps are updated key by key
+// and keys no longer set are removed. It reports whether file changed.
+func updateMapping(file, current, previous *yaml.Node) bool {
+	changed := false
+	for i := 0; i+1 < len(current.Content); i += 2 {
+		key, value := current.Content[i].Value, current.Content[i+1]
+		old := mappingValue(previous, key)
+		if old != nil && sameNode(value, old) {
+			continue
+		}
+
+		existing := mappingValue(file, key)
+		switch {
+		case existing == nil:
+			file.Content = append(file.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if old == nil || old.Kind != yaml.MappingNode {
+				old = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			updateMapping(existing, value, old)
+		default:
+			replaceValue(existing, value)
+		}
+		changed = true
+	}
+
+	for i := 0; i+1 < len(previous.Content); i += 2 {
+		key := previous.Content[i].Value
+		if mappingValue(current, key) == nil && removeKey(file, key) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeKey removes key from a mapping node, reporting whether it was there
+func removeKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// sameNode reports whether two nodes hold the same value
+func sameNode(a, b *yaml.Node) bool {
+	aData, err1 := yaml.Marshal(a)
+	bData, err2 := yaml.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(aData, bData)
+}
+
+// replaceValue replaces the value of a node in the file, keeping its
+// comments and, where it still applies, its quoting or flow style
+func replaceValue(existing, value *yaml.Node) {
+	kept := *existing
+	*existing = *value
+	existing.HeadComment = kept.HeadComment
+	existing.LineComment = kept.LineComment
+	existing.FootComment = kept.FootComment
+
+	switch {
+	case kept.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && value.Tag == "!!str":
+		if kept.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			existing.Style = kept.Style
+		}
+	case kept.Kind == value.Kind && kept.Style&yaml.FlowStyle != 0:
+		existing.Style |= yaml.FlowStyle
+	}
+}
+
+// detectIndent returns the indentation of the first indented line in a
+// YAML file, so edits keep it, or 2
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent >= 2 && indent <= 8 {
+			return indent
+		}
+		break
+	}
+	return 2
+}
+
+// encodeYAML encodes a document node with the given indentation
+func encodeYAML(doc *yaml.Node, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON writes a node as indented JSON, keeping the order of keys
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, close, step := "{", "}", 2
+		if node.Kind == yaml.SequenceNode {
+			open, close, step = "[", "]", 1
+		}
+		if len(node.Content) == 0 {
+			buf.WriteString(open + close)
+			return nil
+		}
+
+		buf.WriteString(open + "\n")
+		for i := 0; i < len(node.Content); i += step {
+			buf.WriteString(indent + "  ")
+			if step == 2 {
+				key, err := json.Marshal(node.Content[i].Value)
+				if err != nil {
+					return err
+				}
+				buf.Write(key)
+				buf.WriteString(": ")
+			}
+			if err := writeJSON(buf, node.Content[i+step-1], indent+"  "); err != nil {
+				return err
+			}
+			if i+step < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + close)
+		return nil
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias, indent)
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
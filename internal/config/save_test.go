@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveKeepsComments(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	original := `# Site settings
+title: Old Title # shown in the header
+baseURL: "https://example.org/"
+tags: [go, web]
+params:
+    hero:
+        title: Hi # hero heading
+        image: hero.png
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfigFile(dir)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	cfg.Title = "My Blog"
+	cfg.SummaryLength = 30
+	cfg.Params["hero"].(map[string]interface{})["title"] = "Hello"
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	want := `# Site settings
+title: My Blog # shown in the header
+baseURL: "https://example.org/"
+tags: [go, web]
+params:
+    hero:
+        title: Hello # hero heading
+        image: hero.png
+summaryLength: 30
+`
+	if string(data) != want {
+		t.Errorf("Expected config:\n%s\ngot:\n%s", want, data)
+	}
+}
+
+func TestSaveLeavesOutDefaults(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := DefaultConfig()
+	cfg.Title = "My Blog"
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != "title: My Blog\n" {
+		t.Errorf("Expected only the title to be written, got:\n%s", data)
+	}
+
+	// Saving again without changes leaves the file alone
+	if err := os.WriteFile(filepath.Join(dir, "config.yml"), []byte("title:   My Blog\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "config.yml"))
+	if string(data) != "title:   My Blog\n" {
+		t.Errorf("Expected the unchanged file to be left alone, got:\n%s", data)
+	}
+}
+
+func TestSaveRemovesUnsetKeys(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(configPath, []byte("title: Blog\nminify: true\nparams:\n  a: 1\n  b: 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfigFile(dir)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	cfg.Minify = false
+	delete(cfg.Params, "a")
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if want := "title: Blog\nparams:\n  b: 2\n"; string(data) != want {
+		t.Errorf("Expected config:\n%s\ngot:\n%s", want, data)
+	}
+}
+
+func TestSaveJSONKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.jsonc")
+	original := "{\n  // The site\n  \"theme\": \"custom\",\n  \"title\": \"Old\",\n}\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfigFile(dir)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if err := cfg.Set("title", "New"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if want := "{\n  \"theme\": \"custom\",\n  \"title\": \"New\"\n}\n"; string(data) != want {
+		t.Errorf("Expected config:\n%s\ngot:\n%s", want, data)
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Params = map[string]interface{}{"hero": map[string]interface{}{"image": "hero.png"}}
+
+	if err := cfg.Set("title", "My Blog"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Set("tags", "[go, web]"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Set("imaging.quality", "80"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Set("params.hero.title", "Hello"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if cfg.Title != "My Blog" {
+		t.Errorf("Expected title 'My Blog', got %q", cfg.Title)
+	}
+	if len(cfg.Tags) != 2 || cfg.Tags[1] != "web" {
+		t.Errorf("Expected tags [go web], got %v", cfg.Tags)
+	}
+	if cfg.Imaging.Quality != 80 {
+		t.Errorf("Expected imaging quality 80, got %d", cfg.Imaging.Quality)
+	}
+	hero := cfg.Params["hero"].(map[string]interface{})
+	if hero["title"] != "Hello" || hero["image"] != "hero.png" {
+		t.Errorf("Expected params.hero to be merged, got %v", hero)
+	}
+
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"titel", "x", `unknown key "titel" (did you mean "title"?)`},
+		{"title.main", "x", `"title" is not a map of settings`},
+		{"summaryLength", "many", "invalid value for summaryLength"},
+		{"params..title", "x", `invalid key "params."`},
+	}
+	for _, tt := range tests {
+		err := cfg.Set(tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q for %s, got %v", tt.want, tt.key, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// requiredLayouts are the layouts every site needs, from the site or its
+// theme. Other layouts fall back to these.
+var requiredLayouts = []string{"base.html", "single.html", "list.html"}
+
+// Validate checks that the config values make sense. All problems are
+// reported together, with the file and line that set each value.
+func (c Config) Validate() error {
+	var problems []string
+	report := func(key, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		if source := c.Source(key); source != "" {
+			message = source + ": " + message
+		}
+		problems = append(problems, message)
+	}
+
+	if !validBaseURL(c.BaseURL) {
+		report("baseURL", "baseURL %q must be an http or https URL such as https://example.com/, or a root-relative path such as /", c.BaseURL)
+	}
+
+	dirs := []struct {
+		key   string
+		value string
+	}{
+		{"contentDir", c.ContentDir},
+		{"layoutDir", c.LayoutDir},
+		{"staticDir", c.StaticDir},
+		{"outputDir", c.OutputDir},
+	}
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir.value) == "" {
+			report(dir.key, "%s must not be empty", dir.key)
+		}
+	}
+	// The output directory must not replace the site itself or its sources
+	if output := filepath.Clean(c.OutputDir); c.OutputDir != "" {
+		if output == "." || output == ".." {
+			report("outputDir", "outputDir %q must be a directory inside the site", c.OutputDir)
+		}
+		for _, dir := range dirs[:3] {
+			if dir.value != "" && filepath.Clean(dir.value) == output {
+				report("outputDir", "outputDir %q is also the %s", c.OutputDir, dir.key)
+			}
+		}
+	}
+
+	if c.SummaryLength < 0 {
+		report("summaryLength", "summaryLength must not be negative, got %d", c.SummaryLength)
+	}
+	if c.Imaging.Quality < 0 || c.Imaging.Quality > 100 {
+		report("imaging.quality", "imaging.quality must be between 1 and 100, got %d", c.Imaging.Quality)
+	}
+	for _, width := range c.Imaging.Widths {
+		if width <= 0 {
+			report("imaging.widths", "imaging.widths must be positive, got %d", width)
+			break
+		}
+	}
+	for i, bundle := range c.CSSBundles {
+		if strings.TrimSpace(bundle.Entry) == "" {
+			report("cssBundles", "cssBundles[%d] has no entry", i)
+		}
+	}
+
+	for name, weight := range c.Related.Weights {
+		if name != "tags" && name != "section" {
+			report("related.weights."+name, "related.weights.%s is unknown, use tags or section", name)
+		} else if weight < 0 {
+			report("related.weights."+name, "related.weights.%s must not be negative, got %g", name, weight)
+		}
+	}
+	if c.Related.DateWeight < 0 {
+		report("related.dateWeight", "related.dateWeight must not be negative, got %g", c.Related.DateWeight)
+	}
+	if c.Related.DateRange <= 0 {
+		report("related.dateRange", "related.dateRange must be a positive number of days, got %d", c.Related.DateRange)
+	}
+	if c.Related.Limit <= 0 {
+		report("related.limit", "related.limit must be positive, got %d", c.Related.Limit)
+	}
+
+	if c.IsMultilingual() {
+		if _, ok := c.Languages[c.Language]; !ok {
+			codes := make([]string, 0, len(c.Languages))
+			for _, lang := range c.LanguageList() {
+				codes = append(codes, lang.Code)
+			}
+			report("language", "language %q must be one of the languages (%s)", c.Language, strings.Join(codes, ", "))
+		}
+		for _, lang := range c.LanguageList() {
+			key := "languages." + lang.Code
+			if lang.Code == "" || strings.ContainsAny(lang.Code, `/\.`) {
+				report(key, "language code %q must be a single path segment such as \"de\"", lang.Code)
+			}
+			if lang.BaseURL == "" {
+				continue
+			}
+			if !validBaseURL(lang.BaseURL) {
+				report(key+".baseURL", "%s.baseURL %q must be an http or https URL or a root-relative path", key, lang.BaseURL)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// validBaseURL reports whether baseURL is an absolute http or https URL,
+// a root-relative path such as "/" for local previews, or empty
+func validBaseURL(baseURL string) bool {
+	if baseURL == "" {
+		return true
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(u.Path, "/")
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ValidateSite checks the config against the site at sitePath: the theme
+// must exist, and the required layouts must be in the site or the theme
+func (c Config) ValidateSite(sitePath string) error {
+	themesPath := filepath.Join(sitePath, "themes")
+	if c.Theme != "" {
+		if info, err := os.Stat(filepath.Join(themesPath, c.Theme)); err != nil || !info.IsDir() {
+			message := fmt.Sprintf("theme %q not found in %s", c.Theme, themesPath)
+			if entries, err := os.ReadDir(themesPath); err == nil {
+				var themes []string
+				for _, entry := range entries {
+					if entry.IsDir() {
+						themes = append(themes, entry.Name())
+					}
+				}
+				if suggestion := suggest(c.Theme, themes); suggestion != "" {
+					message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+			}
+			if source := c.Source("theme"); source != "" {
+				message = source + ": " + message
+			}
+			return errors.New(message)
+		}
+	}
+
+	var missing []string
+	for _, layout := range requiredLayouts {
+		found := false
+		for _, dir := range []string{
+			filepath.Join(sitePath, c.LayoutDir),
+			filepath.Join(themesPath, c.Theme, "layouts"),
+		} {
+			if _, err := os.Stat(filepath.Join(dir, layout)); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, layout)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing layouts %s: add them to %s or use a theme that has them",
+			strings.Join(missing, ", "), filepath.Join(sitePath, c.LayoutDir))
+	}
+	return nil
+}
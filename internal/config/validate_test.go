@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("Expected the default config to be valid, got %v", err)
+	}
+
+	// Root-relative and empty base URLs are fine for local previews
+	for _, baseURL := range []string{"", "/", "/docs/", "http://localhost:8080/"} {
+		cfg := DefaultConfig()
+		cfg.BaseURL = baseURL
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected baseURL %q to be valid, got %v", baseURL, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"relative baseURL", func(c *Config) { c.BaseURL = "example.com" }, "baseURL"},
+		{"bad scheme", func(c *Config) { c.BaseURL = "ftp://example.com/" }, "baseURL"},
+		{"no host", func(c *Config) { c.BaseURL = "https:///docs/" }, "baseURL"},
+		{"empty contentDir", func(c *Config) { c.ContentDir = "" }, "contentDir must not be empty"},
+		{"output in site root", func(c *Config) { c.OutputDir = "./" }, "must be a directory inside the site"},
+		{"output over static", func(c *Config) { c.OutputDir = "static/" }, "is also the staticDir"},
+		{"negative summaryLength", func(c *Config) { c.SummaryLength = -5 }, "summaryLength must not be negative, got -5"},
+		{"quality", func(c *Config) { c.Imaging.Quality = 120 }, "imaging.quality"},
+		{"widths", func(c *Config) { c.Imaging.Widths = []int{400, 0} }, "imaging.widths"},
+		{"bundle entry", func(c *Config) { c.CSSBundles = []CSSBundle{{Output: "x.css"}} }, "cssBundles[0] has no entry"},
+		{"related weight", func(c *Config) { c.Related.Weights["categories"] = 1 }, "related.weights.categories is unknown"},
+		{"related limit", func(c *Config) { c.Related.Limit = 0 }, "related.limit must be positive"},
+		{"unknown language", func(c *Config) { c.Languages = map[string]Language{"de": {}} }, `language "en" must be one of the languages (de)`},
+		{"language code", func(c *Config) { c.Languages = map[string]Language{"en": {}, "de/at": {}} }, `language code "de/at"`},
+		{"language baseURL", func(c *Config) { c.Languages = map[string]Language{"en": {BaseURL: "example.de"}} }, "languages.en.baseURL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsSource(t *testing.T) {
+	tempDir := t.TempDir()
+	config := "title: My Site\nsummaryLength: -5\ncontentDir: \"\"\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(tempDir)
+	if err == nil {
+		t.Fatal("Expected invalid values to fail")
+	}
+	for _, want := range []string{
+		"config.yml:2: summaryLength must not be negative",
+		"config.yml:3: contentDir must not be empty",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestValidateSite(t *testing.T) {
+	sitePath := t.TempDir()
+	layouts := filepath.Join(sitePath, "themes", "default", "layouts")
+	if err := os.MkdirAll(layouts, 0755); err != nil {
+		t.Fatalf("Failed to create theme: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	err := cfg.ValidateSite(sitePath)
+	if err == nil || !strings.Contains(err.Error(), "missing layouts base.html, single.html, list.html") {
+		t.Errorf("Expected missing layouts error, got %v", err)
+	}
+
+	// Layouts may come from the theme or the site
+	for _, file := range []string{
+		filepath.Join(layouts, "base.html"),
+		filepath.Join(layouts, "single.html"),
+		filepath.Join(sitePath, "layouts", "list.html"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(""), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+	if err := cfg.ValidateSite(sitePath); err != nil {
+		t.Errorf("Expected site to be valid, got %v", err)
+	}
+
+	cfg.Theme = "defualt"
+	err = cfg.ValidateSite(sitePath)
+	if err == nil || !strings.Contains(err.Error(), `theme "defualt" not found`) || !strings.Contains(err.Error(), `did you mean "default"?`) {
+		t.Errorf("Expected missing theme error with a suggestion, got %v", err)
+	}
+}
@@ -6,17 +6,37 @@ import (
 	"errors"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // FrontMatter represents the metadata at the beginning of content files
 type FrontMatter struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	Tags        []string  `json:"tags"`
-	Draft       bool      `json:"draft"`
-	Layout      string    `json:"layout"`
-	Slug        string    `json:"slug"`
+	Title          string          `json:"title" yaml:"title"`
+	Description    string          `json:"description" yaml:"description"`
+	Date           time.Time       `json:"date" yaml:"date"`
+	Lastmod        time.Time       `json:"lastmod" yaml:"lastmod"`
+	Tags           []string        `json:"tags" yaml:"tags"`
+	Draft          bool            `json:"draft" yaml:"draft"`
+	Layout         string          `json:"layout" yaml:"layout"`
+	Slug           string          `json:"slug" yaml:"slug"`
+	Aliases        []string        `json:"aliases" yaml:"aliases"`
+	Images         []string        `json:"images" yaml:"images"`
+	Canonical      string          `json:"canonical" yaml:"canonical"`
+	Menu           PageMenus       `json:"menu" yaml:"menu"`
+	Lang           string          `json:"lang" yaml:"lang"`
+	TranslationKey string          `json:"translationKey" yaml:"translationKey"`
+	Sitemap        SitemapSettings `json:"sitemap" yaml:"sitemap"`
+}
+
+// SitemapSettings control how a page appears in the sitemap
+type SitemapSettings struct {
+	// Exclude leaves the page out of the sitemap
+	Exclude bool `json:"exclude" yaml:"exclude"`
+	// Priority overrides the default priority (0.0-1.0) when set
+	Priority float64 `json:"priority" yaml:"priority"`
+	// ChangeFreq overrides the default change frequency, e.g. "daily"
+	ChangeFreq string `json:"changefreq" yaml:"changefreq"`
 }
 
 // ParseFrontMatter extracts and parses front matter from content
@@ -37,6 +57,15 @@ func ParseFrontMatter(content []byte) (FrontMatter, []byte, error) {
 	rawYAML := parts[0]
 	bodyContent := parts[1]
 
+	// Parse as YAML, which supports indented lists and nested values
+	if err := yaml.Unmarshal(rawYAML, &frontMatter); err == nil {
+		return frontMatter, bodyContent, nil
+	}
+
+	// Fall back to the lenient parser for front matter that isn't strictly
+	// valid YAML, e.g. unquoted titles containing ": "
+	frontMatter = FrontMatter{}
+
 	// Convert YAML to JSON-compatible format
 	jsonData, err := yamlToJSON(rawYAML)
 	if err != nil {
@@ -0,0 +1,98 @@
+package content
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	data := []byte(`---
+title: "Hello: World"
+date: 2024-02-01T10:00:00Z
+tags:
+  - intro
+  - go
+aliases: [/old/]
+draft: true
+---
+Body`)
+
+	fm, body, err := ParseFrontMatter(data)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter failed: %v", err)
+	}
+	if fm.Title != "Hello: World" {
+		t.Errorf("Expected title 'Hello: World', got %q", fm.Title)
+	}
+	if !fm.Date.Equal(time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %v", fm.Date)
+	}
+	if len(fm.Tags) != 2 || fm.Tags[0] != "intro" || fm.Tags[1] != "go" {
+		t.Errorf("Expected indented tag list to be parsed, got %v", fm.Tags)
+	}
+	if len(fm.Aliases) != 1 || fm.Aliases[0] != "/old/" {
+		t.Errorf("Expected aliases [/old/], got %v", fm.Aliases)
+	}
+	if !fm.Draft {
+		t.Errorf("Expected draft to be true")
+	}
+	if string(body) != "Body" {
+		t.Errorf("Expected body 'Body', got %q", body)
+	}
+}
+
+func TestParseFrontMatterLenient(t *testing.T) {
+	// Not valid YAML, but accepted as before
+	data := []byte("---\ntitle: Go: A Tour\ntags: [a, b]\n---\nBody")
+
+	fm, _, err := ParseFrontMatter(data)
+	if err != nil {
+		t.Fatalf("ParseFrontMatter failed: %v", err)
+	}
+	if fm.Title != "Go: A Tour" {
+		t.Errorf("Expected title 'Go: A Tour', got %q", fm.Title)
+	}
+	if len(fm.Tags) != 2 {
+		t.Errorf("Expected 2 tags, got %v", fm.Tags)
+	}
+}
+
+func TestParseFrontMatterMenu(t *testing.T) {
+	tests := []struct {
+		name  string
+		menu  string
+		want  PageMenus
+		isNil bool
+	}{
+		{"name", "menu: main", PageMenus{"main": {}}, false},
+		{"list", "menu: [main, footer]", PageMenus{"main": {}, "footer": {}}, false},
+		{"map", "menu:\n  main:\n    name: Start\n    weight: 10\n    parent: docs\n  footer:", PageMenus{
+			"main":   {Name: "Start", Weight: 10, Parent: "docs"},
+			"footer": {},
+		}, false},
+		{"none", "title: No menu", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, _, err := ParseFrontMatter([]byte("---\n" + tt.menu + "\n---\nBody"))
+			if err != nil {
+				t.Fatalf("ParseFrontMatter failed: %v", err)
+			}
+			if tt.isNil {
+				if fm.Menu != nil {
+					t.Errorf("Expected no menus, got %v", fm.Menu)
+				}
+				return
+			}
+			if len(fm.Menu) != len(tt.want) {
+				t.Fatalf("Expected menus %v, got %v", tt.want, fm.Menu)
+			}
+			for name, want := range tt.want {
+				if got, ok := fm.Menu[name]; !ok || got != want {
+					t.Errorf("Expected menu %s to be %+v, got %+v", name, want, got)
+				}
+			}
+		})
+	}
+}
@@ -2,12 +2,58 @@ package content
 
 import (
 	"fmt"
+	"html"
 	"regexp"
 	"strings"
 )
 
+// ImageRenderer renders a Markdown image as HTML. Returning an empty
+// string falls back to a plain <img> tag.
+type ImageRenderer func(src, alt, title string) (string, error)
+
+// RefResolver resolves a reference to another content file, such as
+// "../other-post.md#anchor", to its URL. absolute selects the permalink
+// instead of the root-relative URL.
+type RefResolver func(ref string, absolute bool) (string, error)
+
+// Hooks customize how RenderMarkdown renders images and cross-references.
+// Nil hooks keep the default output.
+type Hooks struct {
+	Image ImageRenderer
+	Ref   RefResolver
+}
+
+var (
+	// imageRe matches ![alt](src) and ![alt](src "title")
+	imageRe = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+"([^"]*)")?\s*\)`)
+
+	// refShortcodeRe matches {{< ref "path.md" >}} and {{< relref "path.md" >}}
+	refShortcodeRe = regexp.MustCompile(`\{\{<\s*(ref|relref)\s+"([^"]+)"\s*>\}\}`)
+)
+
+// lineError records the Markdown line an error occurred on
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
 // MarkdownToHTML converts Markdown content to HTML
 func MarkdownToHTML(markdown []byte) []byte {
+	result, _ := RenderMarkdown(markdown, Hooks{})
+	return result
+}
+
+// RenderMarkdown converts Markdown content to HTML using hooks for images
+// and references to other content files
+func RenderMarkdown(markdown []byte, hooks Hooks) ([]byte, error) {
 	// Simple Markdown to HTML conversion
 	html := string(markdown)
 
@@ -24,6 +70,19 @@ func MarkdownToHTML(markdown []byte) []byte {
 		return placeholder
 	})
 
+	// Protect inline code the same way, so link syntax quoted in it is
+	// left alone
+	inlineCodeRe := regexp.MustCompile("`([^`]+)`")
+	inlineCodes := make(map[string]string)
+	inlineCodeCount := 0
+	html = inlineCodeRe.ReplaceAllStringFunc(html, func(match string) string {
+		code := inlineCodeRe.FindStringSubmatch(match)[1]
+		placeholder := fmt.Sprintf("___INLINE_CODE_%d___", inlineCodeCount)
+		inlineCodes[placeholder] = "<code>" + code + "</code>"
+		inlineCodeCount++
+		return placeholder
+	})
+
 	// Process Headers
 	headerRe := regexp.MustCompile(`(?m)^(#{1,6})\s+(.+)$`)
 	headers := make(map[string]string)
@@ -38,6 +97,34 @@ func MarkdownToHTML(markdown []byte) []byte {
 		return placeholder
 	})
 
+	// Process images before links, which share their syntax
+	images := make(map[string]string)
+	imageCount := 0
+	var imageErr error
+	html = imageRe.ReplaceAllStringFunc(html, func(match string) string {
+		submatches := imageRe.FindStringSubmatch(match)
+		alt, src, title := submatches[1], submatches[2], submatches[3]
+
+		var img string
+		if hooks.Image != nil && imageErr == nil {
+			var err error
+			if img, err = hooks.Image(src, alt, title); err != nil {
+				imageErr = &lineError{line: lineOf(markdown, match), err: err}
+			}
+		}
+		if img == "" {
+			img = PlainImage(src, alt, title)
+		}
+
+		placeholder := fmt.Sprintf("___IMAGE_%d___", imageCount)
+		images[placeholder] = img
+		imageCount++
+		return placeholder
+	})
+	if imageErr != nil {
+		return nil, imageErr
+	}
+
 	// Process regular inline elements
 	// Bold
 	boldRe := regexp.MustCompile(`\*\*(.+?)\*\*`)
@@ -47,13 +134,37 @@ func MarkdownToHTML(markdown []byte) []byte {
 	italicRe := regexp.MustCompile(`\*(.+?)\*`)
 	html = italicRe.ReplaceAllString(html, "<em>$1</em>")
 
+	// References to other content files
+	var refErr error
+	resolveRef := func(ref string, absolute bool, match string) string {
+		if hooks.Ref == nil || refErr != nil {
+			return ref
+		}
+		url, err := hooks.Ref(ref, absolute)
+		if err != nil {
+			refErr = &lineError{line: lineOf(markdown, match), err: err}
+			return ref
+		}
+		return url
+	}
+	html = refShortcodeRe.ReplaceAllStringFunc(html, func(match string) string {
+		submatches := refShortcodeRe.FindStringSubmatch(match)
+		return resolveRef(submatches[2], submatches[1] == "ref", match)
+	})
+
 	// Links
 	linkRe := regexp.MustCompile(`\[(.+?)\]\((.+?)\)`)
-	html = linkRe.ReplaceAllString(html, "<a href=\"$2\">$1</a>")
-
-	// Inline code
-	inlineCodeRe := regexp.MustCompile("`([^`]+)`")
-	html = inlineCodeRe.ReplaceAllString(html, "<code>$1</code>")
+	html = linkRe.ReplaceAllStringFunc(html, func(match string) string {
+		submatches := linkRe.FindStringSubmatch(match)
+		text, dest := submatches[1], submatches[2]
+		if isContentRef(dest) {
+			dest = resolveRef(dest, false, "("+dest+")")
+		}
+		return "<a href=\"" + dest + "\">" + text + "</a>"
+	})
+	if refErr != nil {
+		return nil, refErr
+	}
 
 	// Process lists
 	listItemRe := regexp.MustCompile(`(?m)^-\s+(.+)$`)
@@ -109,8 +220,52 @@ func MarkdownToHTML(markdown []byte) []byte {
 		html = strings.Replace(html, placeholder, header, 1)
 	}
 
+	// Restore inline code, which headers may contain
+	for placeholder, code := range inlineCodes {
+		html = strings.Replace(html, placeholder, code, 1)
+	}
+
+	// Restore images
+	for placeholder, img := range images {
+		html = strings.Replace(html, placeholder, img, 1)
+	}
+
 	// Clean up empty paragraphs
 	html = strings.ReplaceAll(html, "<p></p>", "")
 
-	return []byte(html)
+	return []byte(html), nil
+}
+
+// isContentRef reports whether a link destination points at another
+// Markdown file of the site, e.g. "../other-post.md#anchor"
+func isContentRef(dest string) bool {
+	if strings.Contains(dest, "://") || strings.HasPrefix(dest, "//") || strings.HasPrefix(dest, "mailto:") {
+		return false
+	}
+	refPath := dest
+	if idx := strings.IndexAny(refPath, "?#"); idx >= 0 {
+		refPath = refPath[:idx]
+	}
+	return strings.HasSuffix(strings.ToLower(refPath), ".md")
+}
+
+// lineOf returns the 1-based line of the first occurrence of needle in
+// markdown, or 0 if it doesn't occur
+func lineOf(markdown []byte, needle string) int {
+	idx := strings.Index(string(markdown), needle)
+	if idx < 0 {
+		return 0
+	}
+	return strings.Count(string(markdown[:idx]), "\n") + 1
+}
+
+// PlainImage returns an <img> tag that loads lazily
+func PlainImage(src, alt, title string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<img src="%s" alt="%s"`, html.EscapeString(src), html.EscapeString(alt))
+	if title != "" {
+		fmt.Fprintf(&sb, ` title="%s"`, html.EscapeString(title))
+	}
+	sb.WriteString(` loading="lazy" decoding="async">`)
+	return sb.String()
 }
\ No newline at end of file
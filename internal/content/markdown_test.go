@@ -31,6 +31,16 @@ func TestMarkdownToHTML(t *testing.T) {
 			markdown: "This is a [link](https://example.com)",
 			want:     "<p>This is a <a href=\"https://example.com\">link</a></p>",
 		},
+		{
+			name:     "Images",
+			markdown: "![A \"photo\"](photo.jpg \"Title\")",
+			want:     "<p><img src=\"photo.jpg\" alt=\"A &#34;photo&#34;\" title=\"Title\" loading=\"lazy\" decoding=\"async\"></p>",
+		},
+		{
+			name:     "Image inside link",
+			markdown: "See [![Logo](/logo.png)](https://example.com)",
+			want:     "<p>See <a href=\"https://example.com\"><img src=\"/logo.png\" alt=\"Logo\" loading=\"lazy\" decoding=\"async\"></a></p>",
+		},
 		{
 			name:     "Lists",
 			markdown: "- Item 1\n- Item 2",
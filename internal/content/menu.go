@@ -0,0 +1,75 @@
+package content
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PageMenu places a page in a menu; empty fields take their defaults from
+// the page
+type PageMenu struct {
+	Identifier string `json:"identifier" yaml:"identifier"`
+	Name       string `json:"name" yaml:"name"`
+	Weight     int    `json:"weight" yaml:"weight"`
+	Parent     string `json:"parent" yaml:"parent"`
+}
+
+// PageMenus maps menu names to a page's entry in them. In front matter it
+// may be a single menu name, a list of names, or a map of names to
+// settings:
+//
+//	menu: main
+//	menu: [main, footer]
+//	menu:
+//	  main:
+//	    weight: 10
+//	    parent: docs
+type PageMenus map[string]PageMenu
+
+// UnmarshalYAML accepts a menu name, a list of names or a map of settings
+func (m *PageMenus) UnmarshalYAML(value *yaml.Node) error {
+	if value.Tag == "!!null" {
+		*m = nil
+		return nil
+	}
+
+	menus := make(PageMenus)
+	switch value.Kind {
+	case yaml.ScalarNode:
+		menus[value.Value] = PageMenu{}
+	case yaml.SequenceNode:
+		var names []string
+		if err := value.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			menus[name] = PageMenu{}
+		}
+	case yaml.MappingNode:
+		var settings map[string]*PageMenu
+		if err := value.Decode(&settings); err != nil {
+			return err
+		}
+		for name, entry := range settings {
+			if entry == nil {
+				entry = &PageMenu{}
+			}
+			menus[name] = *entry
+		}
+	default:
+		return fmt.Errorf("line %d: menu must be a name, a list of names or a map", value.Line)
+	}
+	*m = menus
+	return nil
+}
+
+// UnmarshalJSON accepts the same forms as UnmarshalYAML
+func (m *PageMenus) UnmarshalJSON(data []byte) error {
+	var node yaml.Node
+	// JSON is valid YAML
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	return m.UnmarshalYAML(node.Content[0])
+}
@@ -1,10 +1,15 @@
 package content
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/dikaio/scribe/internal/config"
 )
 
 // Page represents a content page
@@ -21,7 +26,53 @@ type Page struct {
 	Path        string
 	URL         string
 	Permalink   string
-	IsPost      bool
+	// RelPermalink is the root-relative URL including the base path of
+	// the site, e.g. "/docs/about/" for a baseURL of https://example.com/docs/
+	RelPermalink string
+	IsPost       bool
+	// Section is the top-level content directory, e.g. "posts"
+	Section string
+	// Aliases are old URLs that redirect to the page
+	Aliases []string
+	// Lastmod is the time the page was last modified, from front matter
+	// or the git history; it is zero when unknown
+	Lastmod time.Time
+	// Lang is the language code of the page; empty means the site language
+	Lang string
+	// TranslationKey links translations of the same page
+	TranslationKey string
+	// Sitemap holds the page's sitemap settings
+	Sitemap SitemapSettings
+	// Images are used for social cards: site paths, absolute URLs, or
+	// paths relative to the page bundle
+	Images []string
+	// Canonical overrides the canonical URL of the page
+	Canonical string
+	// Menus lists the menus the page adds itself to
+	Menus PageMenus
+	// Translations are the pages in other languages with the same
+	// TranslationKey, in the order of the site's languages
+	Translations []Page
+	// Prev and Next are the pages published before and after this one;
+	// nil for the oldest and the newest page
+	Prev *Page
+	Next *Page
+	// PrevInSection and NextInSection are the same within the page's
+	// section
+	PrevInSection *Page
+	NextInSection *Page
+
+	// dir is the directory of the file relative to the content directory
+	dir string
+	// relPath is the slash separated path of the page relative to its
+	// content directory, without a language suffix, e.g. "posts/hello.md"
+	// for posts/hello.de.md; empty when unknown
+	relPath string
+	// relatedIndex finds the related pages of the build's lists of pages
+	relatedIndex *relatedIndex
+	// bodyLine is the number of lines before the Markdown body, used to
+	// report errors at their line in the source file
+	bodyLine int
 }
 
 // extractContentPath extracts the URL path from the file path
@@ -35,8 +86,12 @@ func extractContentPath(filePath string, slug string) string {
 	}
 
 	// Get the path after "content/"
-	relativePath := filePath[contentIdx+9:] // +9 for "/content/"
-	
+	return contentPath(filePath[contentIdx+9:], slug) // +9 for "/content/"
+}
+
+// contentPath returns the URL path of a file at relativePath in the
+// content directory, replacing its name with slug
+func contentPath(relativePath string, slug string) string {
 	// Replace the file name with the slug
 	dir := filepath.Dir(relativePath)
 	if dir == "." {
@@ -44,18 +99,41 @@ func extractContentPath(filePath string, slug string) string {
 		return slug
 	}
 	
-	// Handle subdirectories correctly
-	// Keep directory structure for all content
-	if dir != "." {
-		return filepath.Join(dir, slug)
+	// A page bundle (a directory with an index.md next to its images and
+	// other files) takes the URL of the directory
+	if slug == "index" && filepath.Base(relativePath) == "index.md" {
+		return dir
 	}
-	
-	// For all cases, use the slug (which is either the custom slug provided 
-	// in frontmatter or the filename without extension)
+
+	// Keep directory structure for all content; the slug is either the
+	// custom slug provided in frontmatter or the filename without extension
 	return filepath.Join(dir, slug)
 }
 
 func LoadPage(filePath string, baseURL string, trailingSlash bool) (Page, error) {
+	page, err := ParsePage(filePath, baseURL, trailingSlash)
+	if err != nil {
+		return page, err
+	}
+	err = page.RenderHTML(Hooks{})
+	return page, err
+}
+
+// ParsePage reads a page's front matter and determines its URL without
+// converting the Markdown body; call RenderHTML once the pages it may
+// reference are known
+func ParsePage(filePath string, baseURL string, trailingSlash bool) (Page, error) {
+	relPath := ""
+	if contentIdx := strings.Index(filePath, "/content/"); contentIdx >= 0 {
+		relPath = filePath[contentIdx+9:] // +9 for "/content/"
+	}
+	return ParsePageAt(filePath, relPath, baseURL, trailingSlash)
+}
+
+// ParsePageAt is ParsePage for a file whose path relative to its content
+// directory is known. relPath determines the page's URL and section, so
+// translations such as about.de.md pass it without the language suffix.
+func ParsePageAt(filePath, relPath string, baseURL string, trailingSlash bool) (Page, error) {
 	var page Page
 
 	// Read file content
@@ -70,32 +148,95 @@ func LoadPage(filePath string, baseURL string, trailingSlash bool) (Page, error)
 		return page, err
 	}
 
-	// Convert markdown to HTML
-	html := MarkdownToHTML(content)
-
 	// Determine if it's a post based on the path
 	// A file is a post if it's in any directory named "posts"
-	relativePath := filePath
-	contentIdx := strings.Index(filePath, "/content/")
-	if contentIdx >= 0 {
-		relativePath = filePath[contentIdx+9:] // +9 for "/content/"
+	relativePath := filepath.ToSlash(relPath)
+	if relPath == "" {
+		relativePath = filePath
 	}
 
 	isPost := strings.HasPrefix(relativePath, "posts/") ||
 		strings.Contains(relativePath, "/posts/")
 
+	// The section is the top-level directory of the content file
+	dir := filepath.ToSlash(filepath.Dir(relativePath))
+	if dir == "." || relPath == "" {
+		dir = ""
+	}
+	section := strings.SplitN(dir, "/", 2)[0]
+
 	// Generate slug from filename if not specified
 	slug := frontMatter.Slug
 	if slug == "" {
 		// This is just for the page metadata - the URL is handled separately
-		baseName := filepath.Base(filePath)
+		baseName := filepath.Base(relativePath)
 		extName := filepath.Ext(baseName)
 		slug = strings.TrimSuffix(baseName, extName)
 	}
 
 	// Determine URL from the file path, preserving directory structure
-	url := extractContentPath(filePath, slug)
+	url := slug
+	if relPath != "" {
+		url = contentPath(relativePath, slug)
+	}
 
+	// Create page
+	page = Page{
+		Title:          frontMatter.Title,
+		Description:    frontMatter.Description,
+		Date:           frontMatter.Date,
+		Tags:           frontMatter.Tags,
+		Draft:          frontMatter.Draft,
+		Layout:         frontMatter.Layout,
+		Slug:           slug,
+		Content:        string(content),
+		Path:           filePath,
+		IsPost:         isPost,
+		Section:        section,
+		Aliases:        frontMatter.Aliases,
+		Lastmod:        frontMatter.Lastmod,
+		Lang:           frontMatter.Lang,
+		TranslationKey: frontMatter.TranslationKey,
+		Sitemap:        frontMatter.Sitemap,
+		Images:         frontMatter.Images,
+		Canonical:      frontMatter.Canonical,
+		Menus:          frontMatter.Menu,
+		dir:            dir,
+		relPath:        filepath.ToSlash(relPath),
+		bodyLine:       bytes.Count(data[:len(data)-len(content)], []byte("\n")),
+	}
+	page.SetURL(url, baseURL, trailingSlash)
+
+	return page, nil
+}
+
+// RenderHTML converts the page's Markdown to HTML. Errors from the hooks
+// are reported with the file and line they occurred on.
+func (p *Page) RenderHTML(hooks Hooks) error {
+	html, err := RenderMarkdown([]byte(p.Content), hooks)
+	if err != nil {
+		var le *lineError
+		if errors.As(err, &le) && le.line > 0 {
+			return fmt.Errorf("%s:%d: %w", p.Path, p.bodyLine+le.line, le.err)
+		}
+		return fmt.Errorf("%s: %w", p.Path, err)
+	}
+	p.HTML = string(html)
+	return nil
+}
+
+// CanonicalURL returns the canonical URL of the page: the canonical front
+// matter value, or the permalink
+func (p Page) CanonicalURL() string {
+	if p.Canonical != "" {
+		return p.Canonical
+	}
+	return p.Permalink
+}
+
+// SetURL sets the page URL, normalizing the trailing slash, and derives
+// the permalink from baseURL
+func (p *Page) SetURL(url, baseURL string, trailingSlash bool) {
 	// Handle trailing slash based on configuration
 	if trailingSlash {
 		// Add trailing slash for clean URLs if not already present
@@ -118,22 +259,8 @@ func LoadPage(filePath string, baseURL string, trailingSlash bool) (Page, error)
 	cleanURL := strings.TrimPrefix(url, "/")
 	permalink = permalink + cleanURL
 
-	// Create page
-	page = Page{
-		Title:       frontMatter.Title,
-		Description: frontMatter.Description,
-		Date:        frontMatter.Date,
-		Tags:        frontMatter.Tags,
-		Draft:       frontMatter.Draft,
-		Layout:      frontMatter.Layout,
-		Slug:        slug,
-		Content:     string(content),
-		HTML:        string(html),
-		Path:        filePath,
-		URL:         url,
-		Permalink:   permalink,
-		IsPost:      isPost,
-	}
-
-	return page, nil
+	p.URL = url
+	p.Permalink = permalink
+	// The root-relative URL keeps the path of baseURL
+	p.RelPermalink = config.Config{BaseURL: baseURL}.BasePath() + cleanURL
 }
@@ -41,6 +41,18 @@ func TestExtractContentPath(t *testing.T) {
 			slug:     "js-tutorial",
 			expected: "articles/tech/js-tutorial",
 		},
+		{
+			name:     "Page bundle",
+			filePath: "/path/to/site/content/posts/trip/index.md",
+			slug:     "index",
+			expected: "posts/trip",
+		},
+		{
+			name:     "Page bundle with custom slug",
+			filePath: "/path/to/site/content/posts/trip/index.md",
+			slug:     "journey",
+			expected: "posts/trip/journey",
+		},
 		{
 			name:     "Fallback when content not in path",
 			filePath: "/some/other/path/javascript.md",
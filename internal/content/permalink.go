@@ -0,0 +1,82 @@
+package content
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// permalinkTokenRe matches tokens such as :year or :slug in a pattern
+var permalinkTokenRe = regexp.MustCompile(`:[a-z]+`)
+
+// ExpandPermalink expands a permalink pattern such as
+// "/blog/:year/:month/:slug/" for page. Supported tokens are :year,
+// :month, :day, :slug, :title, :section, :sections and :filename.
+func ExpandPermalink(pattern string, page Page) (string, error) {
+	var expandErr error
+	url := permalinkTokenRe.ReplaceAllStringFunc(pattern, func(token string) string {
+		switch token {
+		case ":year", ":month", ":day":
+			if page.Date.IsZero() {
+				if expandErr == nil {
+					expandErr = fmt.Errorf("permalink %q uses %s but the page has no date", pattern, token)
+				}
+				return ""
+			}
+			switch token {
+			case ":year":
+				return page.Date.Format("2006")
+			case ":month":
+				return page.Date.Format("01")
+			default:
+				return page.Date.Format("02")
+			}
+		case ":slug":
+			if page.Slug == "index" {
+				return page.filename()
+			}
+			return page.Slug
+		case ":title":
+			if slug := generateSlug(page.Title); slug != "" {
+				return slug
+			}
+			return page.filename()
+		case ":section":
+			return page.Section
+		case ":sections":
+			return page.dir
+		case ":filename":
+			return page.filename()
+		default:
+			if expandErr == nil {
+				expandErr = fmt.Errorf("permalink %q: unknown token %s", pattern, token)
+			}
+			return token
+		}
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	// Collapse the empty segments left by empty tokens
+	for strings.Contains(url, "//") {
+		url = strings.ReplaceAll(url, "//", "/")
+	}
+	return strings.TrimPrefix(url, "/"), nil
+}
+
+// filename returns the file name of the page without its extension or a
+// language suffix; page bundles use the name of their directory
+func (p Page) filename() string {
+	base := filepath.Base(p.Path)
+	if p.relPath != "" {
+		base = path.Base(p.relPath)
+	}
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if name == "index" && p.dir != "" {
+		return filepath.Base(filepath.FromSlash(p.dir))
+	}
+	return name
+}
@@ -0,0 +1,66 @@
+package content
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandPermalink(t *testing.T) {
+	post := Page{
+		Title:   "Hello, World!",
+		Date:    time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC),
+		Slug:    "hello",
+		Path:    "/site/content/blog/2024/hello-world.md",
+		Section: "blog",
+		dir:     "blog/2024",
+	}
+	bundle := Page{
+		Title:   "Setup",
+		Slug:    "index",
+		Path:    "/site/content/docs/guides/setup/index.md",
+		Section: "docs",
+		dir:     "docs/guides/setup",
+	}
+	// posts/hello-world.de.md, whose language suffix isn't part of the URL
+	translation := Page{
+		Title:   "Hallo, Welt!",
+		Slug:    "hello-world",
+		Path:    "/site/content/posts/hello-world.de.md",
+		Section: "posts",
+		dir:     "posts",
+		relPath: "posts/hello-world.md",
+	}
+
+	tests := []struct {
+		pattern  string
+		page     Page
+		expected string
+	}{
+		{pattern: "/blog/:year/:month/:slug/", page: post, expected: "blog/2024/03/hello/"},
+		{pattern: "/:year/:month/:day/:filename/", page: post, expected: "2024/03/07/hello-world/"},
+		{pattern: "/:section/:title/", page: post, expected: "blog/hello-world/"},
+		{pattern: "/:sections/:title/", page: post, expected: "blog/2024/hello-world/"},
+		{pattern: "/:sections/", page: bundle, expected: "docs/guides/setup/"},
+		{pattern: "/:section/:slug/", page: bundle, expected: "docs/setup/"},
+		{pattern: "/:section/:filename/", page: translation, expected: "posts/hello-world/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			result, err := ExpandPermalink(tt.pattern, tt.page)
+			if err != nil {
+				t.Fatalf("ExpandPermalink failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := ExpandPermalink("/:year/:slug/", bundle); err == nil {
+		t.Errorf("Expected an error for a date token on a page without a date")
+	}
+	if _, err := ExpandPermalink("/:author/:slug/", post); err == nil {
+		t.Errorf("Expected an error for an unknown token")
+	}
+}
@@ -0,0 +1,129 @@
+package content
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Index looks up pages by their source file, so links between Markdown
+// files can be resolved to the URLs the pages are published at
+type Index struct {
+	contentPath string
+	pages       map[string]Page
+}
+
+// NewIndex creates an index of pages loaded from contentPath. Translations
+// can also be found by their path without the language suffix, e.g.
+// "about.md" for about.de.md. When two pages share a path the first one
+// wins, so a language can list the default language's pages after its own
+// as fallbacks.
+func NewIndex(contentPath string, pages []Page) *Index {
+	idx := &Index{
+		contentPath: contentPath,
+		pages:       make(map[string]Page, len(pages)),
+	}
+	for _, page := range pages {
+		idx.add(idx.sourcePath(page.Path), page)
+		if page.relPath != "" {
+			idx.add(page.relPath, page)
+		}
+	}
+	return idx
+}
+
+// add indexes a page under name unless another page has it
+func (idx *Index) add(name string, page Page) {
+	if _, ok := idx.pages[name]; !ok {
+		idx.pages[name] = page
+	}
+}
+
+// sourcePath returns the slash separated path of a file relative to the
+// content directory
+func (idx *Index) sourcePath(filePath string) string {
+	rel, err := filepath.Rel(idx.contentPath, filePath)
+	if err != nil {
+		return filepath.ToSlash(filePath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Resolve finds the page a reference points at and returns it with the
+// reference's #fragment. References are relative to the source file from,
+// or to the content directory when they start with "/" or when from is
+// empty. The ".md" extension may be omitted, and a directory refers to its
+// index.md.
+func (idx *Index) Resolve(from, ref string) (Page, string, error) {
+	refPath, fragment := ref, ""
+	if i := strings.Index(refPath, "#"); i >= 0 {
+		refPath, fragment = refPath[:i], refPath[i+1:]
+	}
+
+	// A bare fragment refers to the page itself
+	if refPath == "" && from != "" {
+		if page, ok := idx.pages[idx.sourcePath(from)]; ok {
+			return page, fragment, nil
+		}
+	}
+
+	var candidates []string
+	if strings.HasPrefix(refPath, "/") || from == "" {
+		candidates = append(candidates, path.Clean(strings.TrimPrefix(refPath, "/")))
+	} else {
+		dir := path.Dir(idx.sourcePath(from))
+		candidates = append(candidates, path.Join(dir, refPath))
+		// Fall back to the content root, e.g. "posts/hello.md" from anywhere
+		candidates = append(candidates, path.Clean(refPath))
+	}
+
+	for _, candidate := range candidates {
+		for _, name := range []string{candidate, candidate + ".md", path.Join(candidate, "index.md")} {
+			if page, ok := idx.pages[name]; ok {
+				return page, fragment, nil
+			}
+		}
+	}
+
+	return Page{}, "", fmt.Errorf("unresolved reference %q", ref)
+}
+
+// RelRef returns the root-relative URL of the page ref points at
+func (idx *Index) RelRef(from, ref string) (string, error) {
+	page, fragment, err := idx.Resolve(from, ref)
+	if err != nil {
+		return "", err
+	}
+	return withFragment(page.RelPermalink, fragment), nil
+}
+
+// Ref returns the permalink of the page ref points at
+func (idx *Index) Ref(from, ref string) (string, error) {
+	page, fragment, err := idx.Resolve(from, ref)
+	if err != nil {
+		return "", err
+	}
+	return withFragment(page.Permalink, fragment), nil
+}
+
+// Hooks returns Markdown hooks that resolve references from the page at
+// from
+func (idx *Index) Hooks(from string) Hooks {
+	return Hooks{
+		Ref: func(ref string, absolute bool) (string, error) {
+			if absolute {
+				return idx.Ref(from, ref)
+			}
+			return idx.RelRef(from, ref)
+		},
+	}
+}
+
+// withFragment appends a #fragment to a URL when it is not empty
+func withFragment(url, fragment string) string {
+	if fragment == "" {
+		return url
+	}
+	return url + "#" + fragment
+}
@@ -0,0 +1,112 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexResolve(t *testing.T) {
+	contentPath := filepath.Join("site", "content")
+	pages := []Page{
+		{Path: filepath.Join(contentPath, "about.md"), URL: "about/", Permalink: "https://example.com/about/"},
+		{Path: filepath.Join(contentPath, "posts", "hello.md"), URL: "posts/hello-world/", Permalink: "https://example.com/posts/hello-world/"},
+		{Path: filepath.Join(contentPath, "posts", "trip", "index.md"), URL: "posts/trip/", Permalink: "https://example.com/posts/trip/"},
+	}
+	for i := range pages {
+		pages[i].RelPermalink = "/" + pages[i].URL
+	}
+	idx := NewIndex(contentPath, pages)
+	from := filepath.Join(contentPath, "posts", "other.md")
+
+	tests := []struct {
+		from     string
+		ref      string
+		expected string
+	}{
+		{from: from, ref: "hello.md", expected: "/posts/hello-world/"},
+		{from: from, ref: "./hello.md#intro", expected: "/posts/hello-world/#intro"},
+		{from: from, ref: "../about.md", expected: "/about/"},
+		{from: from, ref: "/about.md", expected: "/about/"},
+		{from: from, ref: "trip", expected: "/posts/trip/"},
+		{from: from, ref: "posts/hello", expected: "/posts/hello-world/"},
+		{from: "", ref: "posts/trip/index.md", expected: "/posts/trip/"},
+		{from: pages[0].Path, ref: "#team", expected: "/about/#team"},
+	}
+	for _, tt := range tests {
+		result, err := idx.RelRef(tt.from, tt.ref)
+		if err != nil {
+			t.Errorf("RelRef(%q) failed: %v", tt.ref, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("RelRef(%q) = %s, want %s", tt.ref, result, tt.expected)
+		}
+	}
+
+	if result, _ := idx.Ref(from, "../about.md#team"); result != "https://example.com/about/#team" {
+		t.Errorf("Unexpected permalink %s", result)
+	}
+
+	if _, err := idx.RelRef(from, "missing.md"); err == nil {
+		t.Errorf("Expected an error for an unresolved reference")
+	}
+}
+
+func TestRenderHTMLReferences(t *testing.T) {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content")
+	if err := os.MkdirAll(filepath.Join(contentPath, "posts"), 0755); err != nil {
+		t.Fatalf("Failed to create content directory: %v", err)
+	}
+
+	files := map[string]string{
+		"posts/hello.md": "---\ntitle: Hello\nslug: hello-world\n---\nHello.",
+		"posts/other.md": "---\ntitle: Other\n---\nSee [hello](hello.md#intro) and {{< ref \"/posts/hello.md\" >}}.\n\nWrite `[text](other.md)` or `{{< ref \"missing.md\" >}}`.\n\n[Broken](missing.md)",
+	}
+	var pages []Page
+	for name, data := range files {
+		path := filepath.Join(contentPath, filepath.FromSlash(name))
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		page, err := ParsePage(path, "https://example.com/", true)
+		if err != nil {
+			t.Fatalf("ParsePage failed: %v", err)
+		}
+		pages = append(pages, page)
+	}
+
+	idx := NewIndex(contentPath, pages)
+	for _, page := range pages {
+		if page.Title != "Other" {
+			continue
+		}
+
+		err := page.RenderHTML(idx.Hooks(page.Path))
+		if err == nil {
+			t.Fatalf("Expected an unresolved reference error")
+		}
+		expected := page.Path + ":8: unresolved reference \"missing.md\""
+		if err.Error() != expected {
+			t.Errorf("Expected error %q, got %q", expected, err.Error())
+		}
+
+		// Without the broken link the references resolve
+		page.Content = strings.Replace(page.Content, "[Broken](missing.md)", "", 1)
+		if err := page.RenderHTML(idx.Hooks(page.Path)); err != nil {
+			t.Fatalf("RenderHTML failed: %v", err)
+		}
+		expectedHTML := `<p>See <a href="/posts/hello-world/#intro">hello</a> and https://example.com/posts/hello-world/.</p>`
+		if !strings.Contains(page.HTML, expectedHTML) {
+			t.Errorf("Expected %q, got %q", expectedHTML, page.HTML)
+		}
+
+		// Links quoted in inline code are left as written
+		expectedCode := `<p>Write <code>[text](other.md)</code> or <code>{{< ref "missing.md" >}}</code>.</p>`
+		if !strings.Contains(page.HTML, expectedCode) {
+			t.Errorf("Expected %q, got %q", expectedCode, page.HTML)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package content
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// Pages is a list of pages, such as .Site.RegularPages
+type Pages []Page
+
+// Related returns the pages in p most related to page, best first. The
+// pages of p must have been passed to LinkPages, which shares the related
+// settings of the build with them; nil otherwise. The results for every
+// page of a list are found together on the first call and reused.
+func (p Pages) Related(page Page) Pages {
+	if len(p) == 0 || p[0].relatedIndex == nil {
+		return nil
+	}
+	return p[0].relatedIndex.related(p, page)
+}
+
+// relatedIndex holds the related pages found in the lists of pages asked
+// about during a build, since templates ask the same list, such as
+// .Site.RegularPages, about every page
+type relatedIndex struct {
+	config config.Related
+	mu     sync.Mutex
+	lists  map[pageList]map[string]Pages
+}
+
+// pageList identifies a list of pages by its first element and length
+type pageList struct {
+	first *Page
+	len   int
+}
+
+// related returns the pages in pages most related to page
+func (idx *relatedIndex) related(pages Pages, page Page) Pages {
+	key := pageList{&pages[0], len(pages)}
+
+	idx.mu.Lock()
+	results, ok := idx.lists[key]
+	if !ok {
+		results = make(map[string]Pages, len(pages))
+		for i, found := range findRelated(pages, idx.config, nil) {
+			results[pages[i].Path] = found
+		}
+		idx.lists[key] = results
+	}
+	idx.mu.Unlock()
+
+	if found, ok := results[page.Path]; ok {
+		return found
+	}
+
+	// A page outside the list is compared with each page in it
+	all := append(append(make([]Page, 0, len(pages)+1), pages...), page)
+	return findRelated(all, idx.config, []int{len(pages)})[len(pages)]
+}
+
+// LinkPages sets the Prev and Next pages of each page by date, both across
+// pages and within sections, and links the pages to a new related index
+// for Pages.Related. The links point into pages, so it must not be
+// reordered or grown afterwards.
+func LinkPages(pages []Page, related config.Related) {
+	// Oldest first; pages published together keep a stable order
+	order := make([]int, len(pages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := pages[order[i]], pages[order[j]]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Path < b.Path
+	})
+
+	last := make(map[string]int)
+	for n, i := range order {
+		page := &pages[i]
+		page.Prev, page.Next, page.PrevInSection, page.NextInSection = nil, nil, nil, nil
+		if n > 0 {
+			prev := &pages[order[n-1]]
+			page.Prev, prev.Next = prev, page
+		}
+		if j, ok := last[page.Section]; ok {
+			prev := &pages[j]
+			page.PrevInSection, prev.NextInSection = prev, page
+		}
+		last[page.Section] = i
+	}
+
+	index := &relatedIndex{
+		config: related,
+		lists:  make(map[pageList]map[string]Pages),
+	}
+	for i := range pages {
+		pages[i].relatedIndex = index
+	}
+}
+
+// relatedPage is a candidate related page and its score
+type relatedPage struct {
+	index int
+	score float64
+}
+
+// findRelated returns the related pages of the pages at targets, or of
+// every page when targets is nil, by index. Candidates are found through
+// the tags and sections they share, so pages with nothing in common are
+// never compared.
+func findRelated(pages []Page, related config.Related, targets []int) []Pages {
+	tagWeight := related.Weights["tags"]
+	sectionWeight := related.Weights["section"]
+
+	tags := make([][]string, len(pages))
+	dates := make([]float64, len(pages))
+	byTag := make(map[string][]int)
+	bySection := make(map[string][]int)
+	for i, page := range pages {
+		tags[i] = uniqueTags(page.Tags)
+		if !page.Date.IsZero() {
+			dates[i] = float64(page.Date.Unix())
+		}
+		for _, tag := range tags[i] {
+			byTag[tag] = append(byTag[tag], i)
+		}
+		if page.Section != "" {
+			bySection[page.Section] = append(bySection[page.Section], i)
+		}
+	}
+
+	dateRange := float64(related.DateRange) * (24 * time.Hour).Seconds()
+	scores := make([]float64, len(pages))
+	var touched []int
+	add := func(i int, score float64) {
+		if scores[i] == 0 {
+			touched = append(touched, i)
+		}
+		scores[i] += score
+	}
+
+	if targets == nil {
+		targets = make([]int, len(pages))
+		for i := range targets {
+			targets[i] = i
+		}
+	}
+
+	results := make([]Pages, len(pages))
+	for _, i := range targets {
+		if tagWeight > 0 {
+			for _, tag := range tags[i] {
+				for _, j := range byTag[tag] {
+					add(j, tagWeight)
+				}
+			}
+		}
+		if section := pages[i].Section; sectionWeight > 0 && section != "" {
+			for _, j := range bySection[section] {
+				add(j, sectionWeight)
+			}
+		}
+
+		// Keep the best scores; the list is short, so insertion is cheap
+		best := make([]relatedPage, 0, related.Limit+1)
+		for _, j := range touched {
+			score := scores[j]
+			scores[j] = 0
+			if j == i {
+				continue
+			}
+			if related.DateWeight > 0 && dateRange > 0 && dates[i] != 0 && dates[j] != 0 {
+				apart := math.Abs(dates[i] - dates[j])
+				if closeness := 1 - apart/dateRange; closeness > 0 {
+					score *= 1 + related.DateWeight*closeness
+				}
+			}
+
+			n := len(best)
+			for n > 0 && ranksBefore(pages, dates, relatedPage{j, score}, best[n-1]) {
+				n--
+			}
+			if n < related.Limit {
+				best = append(best, relatedPage{})
+				copy(best[n+1:], best[n:])
+				best[n] = relatedPage{j, score}
+				if len(best) > related.Limit {
+					best = best[:related.Limit]
+				}
+			}
+		}
+		touched = touched[:0]
+
+		for _, candidate := range best {
+			results[i] = append(results[i], pages[candidate.index])
+		}
+	}
+	return results
+}
+
+// ranksBefore reports whether a is more related than b: by score, then
+// the newer page, then by path
+func ranksBefore(pages []Page, dates []float64, a, b relatedPage) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	if dates[a.index] != dates[b.index] {
+		return dates[a.index] > dates[b.index]
+	}
+	return pages[a.index].Path < pages[b.index].Path
+}
+
+// uniqueTags returns tags without duplicates
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	unique := tags[:0:0]
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,167 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// testPage returns a page published n days into 2024
+func testPage(title, section string, day int, tags ...string) Page {
+	return Page{
+		Title:   title,
+		Path:    "/site/content/" + section + "/" + strings.ToLower(title) + ".md",
+		Section: section,
+		Date:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day),
+		Tags:    tags,
+	}
+}
+
+// titles returns the titles of pages
+func titles(pages []Page) string {
+	var names []string
+	for _, page := range pages {
+		names = append(names, page.Title)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestLinkPagesPrevNext(t *testing.T) {
+	pages := []Page{
+		testPage("C", "posts", 30),
+		testPage("About", "", 5),
+		testPage("A", "posts", 1),
+		testPage("Guide", "docs", 20),
+		testPage("B", "posts", 10),
+	}
+	LinkPages(pages, config.DefaultConfig().Related)
+
+	tests := []struct {
+		page                     int
+		prev, next               string
+		prevSection, nextSection string
+	}{
+		{page: 2, prev: "", next: "About", prevSection: "", nextSection: "B"},
+		{page: 4, prev: "About", next: "Guide", prevSection: "A", nextSection: "C"},
+		{page: 0, prev: "Guide", next: "", prevSection: "B", nextSection: ""},
+		{page: 3, prev: "B", next: "C", prevSection: "", nextSection: ""},
+	}
+	title := func(page *Page) string {
+		if page == nil {
+			return ""
+		}
+		return page.Title
+	}
+	for _, tt := range tests {
+		page := pages[tt.page]
+		got := [4]string{title(page.Prev), title(page.Next), title(page.PrevInSection), title(page.NextInSection)}
+		want := [4]string{tt.prev, tt.next, tt.prevSection, tt.nextSection}
+		if got != want {
+			t.Errorf("%s: expected prev, next, prevInSection, nextInSection %v, got %v", page.Title, want, got)
+		}
+	}
+
+	// The links can be followed
+	if next := pages[2].NextInSection.NextInSection; next == nil || next.Title != "C" {
+		t.Errorf("Expected A's section links to lead to C, got %v", next)
+	}
+}
+
+func TestRelated(t *testing.T) {
+	pages := []Page{
+		testPage("Go", "posts", 100, "go", "web"),
+		testPage("Templates", "posts", 90, "go", "web", "web"),
+		testPage("Goroutines", "posts", 10, "go"),
+		testPage("Channels", "posts", 99, "go"),
+		testPage("Docs", "docs", 100, "web"),
+		testPage("Cooking", "posts", 100, "food"),
+	}
+
+	// Copies made before linking can still be asked about
+	goPage := pages[0]
+
+	related := config.DefaultConfig().Related
+	related.DateWeight = 0
+	LinkPages(pages, related)
+
+	// Two shared tags first, then one, newest first; nothing in common
+	// isn't related
+	if got := titles(Pages(pages).Related(goPage)); got != "Templates Docs Channels Goroutines" {
+		t.Errorf("Expected related pages by shared tags, got %q", got)
+	}
+	if got := titles(Pages(pages).Related(pages[5])); got != "" {
+		t.Errorf("Expected no related pages, got %q", got)
+	}
+
+	// Without dates, ties go to the newest page
+	if got := titles(Pages(pages).Related(pages[2])); got != "Go Channels Templates" {
+		t.Errorf("Expected the newest page first, got %q", got)
+	}
+
+	// Dates close together break ties between shared tags
+	related.DateWeight = 0.5
+	LinkPages(pages, related)
+	if got := titles(Pages(pages).Related(pages[2])); got != "Templates Channels Go" {
+		t.Errorf("Expected the page closest in date first, got %q", got)
+	}
+
+	// Sections count too, and the limit applies
+	related.Weights = map[string]float64{"tags": 1, "section": 2}
+	related.Limit = 2
+	LinkPages(pages, related)
+	if got := titles(Pages(pages).Related(pages[0])); got != "Templates Channels" {
+		t.Errorf("Expected section weights and the limit to apply, got %q", got)
+	}
+}
+
+func TestRelatedFromList(t *testing.T) {
+	pages := []Page{
+		testPage("Go", "posts", 100, "go", "web"),
+		testPage("Templates", "posts", 90, "go", "web"),
+		testPage("Channels", "posts", 99, "go"),
+		testPage("Docs", "docs", 100, "web"),
+		testPage("Cooking", "posts", 100, "food"),
+	}
+	related := config.DefaultConfig().Related
+	related.DateWeight = 0
+
+	// Lists that aren't linked have no related pages
+	if got := Pages(pages).Related(pages[0]); got != nil {
+		t.Errorf("Expected no related pages before LinkPages, got %q", titles(got))
+	}
+	LinkPages(pages, related)
+
+	// Only pages in the list are related
+	posts := Pages{pages[0], pages[1], pages[2], pages[4]}
+	if got := titles(posts.Related(pages[0])); got != "Templates Channels" {
+		t.Errorf("Expected related pages among posts, got %q", got)
+	}
+	if got := titles(Pages(pages).Related(pages[0])); got != "Templates Docs Channels" {
+		t.Errorf("Expected related pages among all pages, got %q", got)
+	}
+
+	// A page outside the list is compared with the pages in it
+	docs := Pages{pages[3]}
+	if got := titles(docs.Related(pages[1])); got != "Docs" {
+		t.Errorf("Expected Docs to be related to Templates, got %q", got)
+	}
+}
+
+func BenchmarkLinkPages(b *testing.B) {
+	pages := make([]Page, 5000)
+	for i := range pages {
+		pages[i] = testPage(fmt.Sprintf("Page%d", i), fmt.Sprintf("section%d", i%5), i%1000,
+			fmt.Sprintf("tag%d", i%50), fmt.Sprintf("tag%d", i%37))
+	}
+	related := config.DefaultConfig().Related
+	related.Weights["section"] = 1
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LinkPages(pages, related)
+		Pages(pages).Related(pages[0])
+	}
+}
@@ -0,0 +1,189 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the data files in dirs into nested maps: data/team/members.yml
+// becomes ["team"]["members"]. Files in later directories take precedence,
+// so pass the theme's data directory before the site's. Missing
+// directories are skipped.
+func Load(dirs ...string) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	for _, dir := range dirs {
+		tree, err := loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		override(merged, tree)
+	}
+	return merged, nil
+}
+
+// loadDir reads the data files below dir
+func loadDir(dir string) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return tree, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden files and directories such as .DS_Store
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if !isDataFile(ext) {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		value, err := Unmarshal(src, ext)
+		if err != nil {
+			return fmt.Errorf("error reading data file %s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/")
+		if err := insert(tree, key, value); err != nil {
+			return fmt.Errorf("error reading data file %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// isDataFile reports whether ext is a supported data file extension
+func isDataFile(ext string) bool {
+	switch ext {
+	case ".yml", ".yaml", ".json", ".toml", ".csv":
+		return true
+	}
+	return false
+}
+
+// Unmarshal decodes a data file by its extension. A CSV file becomes a
+// list of rows, each a map from the column names in the first row to the
+// row's values.
+func Unmarshal(src []byte, ext string) (interface{}, error) {
+	switch strings.ToLower(ext) {
+	case ".yml", ".yaml":
+		var value interface{}
+		if err := yaml.Unmarshal(src, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case ".json":
+		var value interface{}
+		if err := json.Unmarshal(src, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case ".toml":
+		return parseTOML(src)
+	case ".csv":
+		return parseCSV(src)
+	}
+	return nil, fmt.Errorf("unsupported data format %q", ext)
+}
+
+// parseCSV decodes CSV with a header row
+func parseCSV(src []byte) ([]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(src, []byte("\ufeff"))))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []interface{}{}
+	if len(records) == 0 {
+		return rows, nil
+	}
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// insert stores value at key in tree. A file and a directory of the same
+// name, such as team.yml and team/, are merged when the file holds a map.
+func insert(tree map[string]interface{}, key []string, value interface{}) error {
+	for i, name := range key[:len(key)-1] {
+		next, ok := tree[name].(map[string]interface{})
+		if !ok {
+			if _, exists := tree[name]; exists {
+				return fmt.Errorf("%q is already defined by another data file", strings.Join(key[:i+1], "/"))
+			}
+			next = make(map[string]interface{})
+			tree[name] = next
+		}
+		tree = next
+	}
+
+	name := key[len(key)-1]
+	existing, exists := tree[name]
+	if !exists {
+		tree[name] = value
+		return nil
+	}
+
+	existingMap, ok1 := existing.(map[string]interface{})
+	valueMap, ok2 := value.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return fmt.Errorf("%q is already defined by another data file", strings.Join(key, "/"))
+	}
+	for k, v := range valueMap {
+		if _, exists := existingMap[k]; exists {
+			return fmt.Errorf("%q is already defined by another data file", strings.Join(append(key, k), "/"))
+		}
+		existingMap[k] = v
+	}
+	return nil
+}
+
+// override copies src into dst, merging maps present in both
+func override(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dstMap, ok1 := dst[k].(map[string]interface{})
+		srcMap, ok2 := v.(map[string]interface{})
+		if ok1 && ok2 {
+			override(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	themeDir := filepath.Join(root, "theme")
+	siteDir := filepath.Join(root, "site")
+
+	writeFiles(t, themeDir, map[string]string{
+		"social.yml":     "twitter: theme\nmastodon: theme\n",
+		"footer.json":    `{"copyright": "Theme"}`,
+		"team/lead.toml": "name = \"Theme Lead\"\n",
+	})
+	writeFiles(t, siteDir, map[string]string{
+		"social.yml":        "twitter: site\n",
+		"team/members.yaml": "- name: Ada\n  role: Engineer\n- name: Linus\n  role: Maintainer\n",
+		"team/lead.toml":    "name = \"Ada\"\n",
+		"links.csv":         "title,url\nGo,https://go.dev\n\"Hello, World\",https://example.com\n",
+		"specs/widget.json": `{"weight": 1.5, "colors": ["red", "blue"]}`,
+		"README.md":         "Not data",
+		".hidden.yml":       "secret: true\n",
+	})
+
+	got, err := Load(themeDir, siteDir, filepath.Join(root, "missing"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		// Maps are merged, with the site's values taking precedence
+		"social": map[string]interface{}{"twitter": "site", "mastodon": "theme"},
+		"footer": map[string]interface{}{"copyright": "Theme"},
+		"team": map[string]interface{}{
+			"lead": map[string]interface{}{"name": "Ada"},
+			"members": []interface{}{
+				map[string]interface{}{"name": "Ada", "role": "Engineer"},
+				map[string]interface{}{"name": "Linus", "role": "Maintainer"},
+			},
+		},
+		"links": []interface{}{
+			map[string]interface{}{"title": "Go", "url": "https://go.dev"},
+			map[string]interface{}{"title": "Hello, World", "url": "https://example.com"},
+		},
+		"specs": map[string]interface{}{
+			"widget": map[string]interface{}{"weight": 1.5, "colors": []interface{}{"red", "blue"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected data %#v, got %#v", want, got)
+	}
+}
+
+func TestLoadConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "same name",
+			files: map[string]string{"team.yml": "a: 1\n", "team.json": `{"a": 2}`},
+			want:  `"team/a" is already defined`,
+		},
+		{
+			name:  "file and directory",
+			files: map[string]string{"team.yml": "- Ada\n", "team/lead.yml": "name: Ada\n"},
+			want:  `"team" is already defined`,
+		},
+		{
+			name:  "invalid file",
+			files: map[string]string{"broken.json": "{"},
+			want:  "broken.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			_, err := Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadMergesFileAndDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"team.yml":      "name: Core\n",
+		"team/lead.yml": "name: Ada\n",
+	})
+
+	got, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := map[string]interface{}{
+		"team": map[string]interface{}{
+			"name": "Core",
+			"lead": map[string]interface{}{"name": "Ada"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected data %#v, got %#v", want, got)
+	}
+}
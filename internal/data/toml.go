@@ -0,0 +1,770 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// parseTOML decodes a TOML document. Tables become map[string]interface{},
+// arrays []interface{}, integers int64, floats float64 and dates and date
+// times time.Time; local times are kept as strings.
+func parseTOML(src []byte) (map[string]interface{}, error) {
+	p := &tomlParser{
+		src:         strings.TrimPrefix(string(src), "\ufeff"),
+		line:        1,
+		root:        make(map[string]interface{}),
+		kinds:       make(map[uintptr]tableKind),
+		arrayTables: make(map[arrayKey]bool),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+// tomlParser is a recursive descent parser over a TOML document
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+	root map[string]interface{}
+	// kinds records how each table was defined, keyed by tableID
+	kinds map[uintptr]tableKind
+	// arrayTables holds the arrays defined by [[headers]], which are the
+	// only arrays headers may add to
+	arrayTables map[arrayKey]bool
+}
+
+// tableKind is how a table was defined, which decides how it may be
+// extended later
+type tableKind int
+
+const (
+	// implicitTable was created as the parent of another table, like a
+	// for [a.b]; a header may still define it once
+	implicitTable tableKind = iota
+	// headerTable was defined by a [header]
+	headerTable
+	// dottedTable was created by a dotted key such as a.b = 1; only more
+	// dotted keys and the headers of sub-tables may extend it
+	dottedTable
+	// frozenTable is an inline table, or inside one, and can't be extended
+	frozenTable
+)
+
+// arrayKey names an array by the table holding it and its key there
+type arrayKey struct {
+	table uintptr
+	name  string
+}
+
+// tableID identifies a table for kinds and arrayTables
+func tableID(table map[string]interface{}) uintptr {
+	return reflect.ValueOf(table).Pointer()
+}
+
+// errorf returns an error for the current line
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace skips spaces and tabs
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to the end of the line
+func (p *tomlParser) skipComment() {
+	if p.peek() != '#' {
+		return
+	}
+	for !p.eof() && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// newline consumes a line break and reports whether there was one
+func (p *tomlParser) newline() bool {
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if p.peek() == '\n' {
+		p.pos++
+	} else {
+		return false
+	}
+	p.line++
+	return true
+}
+
+// skipBlank skips whitespace, comments and line breaks
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		if !p.newline() {
+			return
+		}
+	}
+}
+
+// endOfLine consumes the rest of a line after a key/value pair or header
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	if !p.eof() && !p.newline() {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	return nil
+}
+
+func (p *tomlParser) parse() error {
+	current := p.root
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+
+		var err error
+		if strings.HasPrefix(p.src[p.pos:], "[[") {
+			current, err = p.arrayTableHeader()
+		} else if p.peek() == '[' {
+			current, err = p.tableHeader()
+		} else {
+			err = p.keyValue(current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// tableHeader parses [a.b] and returns the table
+func (p *tomlParser) tableHeader() (map[string]interface{}, error) {
+	p.pos++
+	p.skipSpace()
+	key, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ']' {
+		return nil, p.errorf("expected ] after table name")
+	}
+	p.pos++
+
+	parent, err := p.table(p.root, key[:len(key)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := key[len(key)-1]
+	switch existing := parent[name].(type) {
+	case nil:
+		table := make(map[string]interface{})
+		parent[name] = table
+		p.kinds[tableID(table)] = headerTable
+		return table, nil
+	case map[string]interface{}:
+		// Tables can be defined once, and not after dotted keys made them
+		if p.kinds[tableID(existing)] != implicitTable {
+			return nil, p.errorf("table %q is already defined", strings.Join(key, "."))
+		}
+		p.kinds[tableID(existing)] = headerTable
+		return existing, nil
+	}
+	return nil, p.errorf("%q is already defined and is not a table", strings.Join(key, "."))
+}
+
+// arrayTableHeader parses [[a.b]], appends a table to the array and
+// returns it
+func (p *tomlParser) arrayTableHeader() (map[string]interface{}, error) {
+	p.pos += 2
+	p.skipSpace()
+	key, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(p.src[p.pos:], "]]") {
+		return nil, p.errorf("expected ]] after table name")
+	}
+	p.pos += 2
+
+	parent, err := p.table(p.root, key[:len(key)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := key[len(key)-1]
+	array := arrayKey{tableID(parent), name}
+	table := make(map[string]interface{})
+	p.kinds[tableID(table)] = headerTable
+	switch existing := parent[name].(type) {
+	case nil:
+		parent[name] = []interface{}{table}
+		p.arrayTables[array] = true
+	case []interface{}:
+		if !p.arrayTables[array] {
+			return nil, p.errorf("%q is already defined and is not an array of tables", strings.Join(key, "."))
+		}
+		parent[name] = append(existing, table)
+	default:
+		return nil, p.errorf("%q is already defined and is not an array of tables", strings.Join(key, "."))
+	}
+	return table, nil
+}
+
+// table returns the table at key below parent for a header, creating
+// missing tables. A key naming an array of tables refers to its last
+// table. Inline tables and other arrays can't be extended.
+func (p *tomlParser) table(parent map[string]interface{}, key []string) (map[string]interface{}, error) {
+	table := parent
+	for i, name := range key {
+		switch existing := table[name].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[name] = next
+			table = next
+		case map[string]interface{}:
+			if p.kinds[tableID(existing)] == frozenTable {
+				return nil, p.errorf("%q is an inline table and can't be extended", strings.Join(key[:i+1], "."))
+			}
+			table = existing
+		case []interface{}:
+			last, ok := lastTable(existing)
+			if !ok || !p.arrayTables[arrayKey{tableID(table), name}] {
+				return nil, p.errorf("%q is already defined and is not a table", strings.Join(key[:i+1], "."))
+			}
+			table = last
+		default:
+			return nil, p.errorf("%q is already defined and is not a table", strings.Join(key[:i+1], "."))
+		}
+	}
+	return table, nil
+}
+
+// dottedTable returns the table at key below parent for a dotted key,
+// creating missing tables. Only tables created by dotted keys can be
+// extended this way.
+func (p *tomlParser) dottedTable(parent map[string]interface{}, key []string) (map[string]interface{}, error) {
+	table := parent
+	for i, name := range key {
+		switch existing := table[name].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[name] = next
+			p.kinds[tableID(next)] = dottedTable
+			table = next
+		case map[string]interface{}:
+			if p.kinds[tableID(existing)] != dottedTable {
+				return nil, p.errorf("table %q is already defined", strings.Join(key[:i+1], "."))
+			}
+			table = existing
+		default:
+			return nil, p.errorf("%q is already defined and is not a table", strings.Join(key[:i+1], "."))
+		}
+	}
+	return table, nil
+}
+
+// freeze marks the tables in an inline value as frozen
+func (p *tomlParser) freeze(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		p.kinds[tableID(v)] = frozenTable
+		for _, item := range v {
+			p.freeze(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			p.freeze(item)
+		}
+	}
+}
+
+// lastTable returns the last element of an array of tables
+func lastTable(array []interface{}) (map[string]interface{}, bool) {
+	if len(array) == 0 {
+		return nil, false
+	}
+	table, ok := array[len(array)-1].(map[string]interface{})
+	return table, ok
+}
+
+// keyValue parses key = value into table
+func (p *tomlParser) keyValue(table map[string]interface{}) error {
+	key, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.errorf("expected = after key %q", strings.Join(key, "."))
+	}
+	p.pos++
+	p.skipSpace()
+
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.dottedTable(table, key[:len(key)-1])
+	if err != nil {
+		return err
+	}
+	name := key[len(key)-1]
+	if _, ok := parent[name]; ok {
+		return p.errorf("duplicate key %q", strings.Join(key, "."))
+	}
+	p.freeze(value)
+	parent[name] = value
+	return nil
+}
+
+// key parses a dotted key and the spaces after it
+func (p *tomlParser) key() ([]string, error) {
+	var key []string
+	for {
+		name, err := p.simpleKey()
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, name)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return key, nil
+		}
+		p.pos++
+		p.skipSpace()
+	}
+}
+
+// simpleKey parses a bare or quoted key
+func (p *tomlParser) simpleKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.basicString()
+	case '\'':
+		return p.literalString()
+	}
+
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.eof() {
+			return "", p.errorf("expected a key")
+		}
+		return "", p.errorf("unexpected %q, expected a key", p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value parses any value
+func (p *tomlParser) value() (interface{}, error) {
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.multilineBasicString()
+	case strings.HasPrefix(rest, `'''`):
+		return p.multilineLiteralString()
+	case strings.HasPrefix(rest, `"`):
+		return p.basicString()
+	case strings.HasPrefix(rest, `'`):
+		return p.literalString()
+	case strings.HasPrefix(rest, "["):
+		return p.array()
+	case strings.HasPrefix(rest, "{"):
+		return p.inlineTable()
+	}
+	return p.scalar()
+}
+
+// array parses [a, b, ...], which may span lines
+func (p *tomlParser) array() ([]interface{}, error) {
+	p.pos++
+	array := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return array, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+
+		p.skipBlank()
+		switch {
+		case p.eof():
+			return nil, p.errorf("unterminated array")
+		case p.peek() == ',':
+			p.pos++
+		case p.peek() == ']':
+			p.pos++
+			return array, nil
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// inlineTable parses {a = 1, b.c = 2}
+func (p *tomlParser) inlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	p.skipBlank()
+	if p.peek() == '}' {
+		p.pos++
+		return table, nil
+	}
+	for {
+		p.skipBlank()
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		switch {
+		case p.eof():
+			return nil, p.errorf("unterminated inline table")
+		case p.peek() == ',':
+			p.pos++
+		case p.peek() == '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// basicString parses "..." with escapes
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// multilineBasicString parses a multi-line basic string with escapes. A
+// line break right after the opening quotes is trimmed, and a backslash at
+// the end of a line trims the whitespace that follows.
+func (p *tomlParser) multilineBasicString() (string, error) {
+	p.pos += 3
+	p.newline()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], `"""`) {
+			p.pos += 3
+			// Up to two quotes may end the content
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				sb.WriteByte('"')
+				p.pos++
+			}
+			return sb.String(), nil
+		}
+
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.lineEndingBackslash():
+			p.skipBlankNoComments()
+		case c == '\\':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		case c == '\n' || strings.HasPrefix(p.src[p.pos:], "\r\n"):
+			sb.WriteByte('\n')
+			p.newline()
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// lineEndingBackslash reports whether the backslash at pos is the last
+// character on its line, ignoring trailing whitespace
+func (p *tomlParser) lineEndingBackslash() bool {
+	rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+	return strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n")
+}
+
+// skipBlankNoComments skips a backslash and the whitespace and line
+// breaks after it
+func (p *tomlParser) skipBlankNoComments() {
+	p.pos++
+	for {
+		p.skipSpace()
+		if !p.newline() {
+			return
+		}
+	}
+}
+
+// escape decodes the escape sequence at pos into sb
+func (p *tomlParser) escape(sb *strings.Builder) error {
+	if p.pos+1 >= len(p.src) {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte(0x1b)
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+n])
+		}
+		sb.WriteRune(rune(code))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// literalString parses '...' without escapes
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for !p.eof() && p.src[p.pos] != '\'' {
+		if p.src[p.pos] == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.src[start:p.pos]
+	p.pos++
+	return s, nil
+}
+
+// multilineLiteralString parses a multi-line literal string, which has
+// no escapes
+func (p *tomlParser) multilineLiteralString() (string, error) {
+	p.pos += 3
+	p.newline()
+	end := strings.Index(p.src[p.pos:], "'''")
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	end += p.pos
+	// Up to two quotes may end the content
+	for i := 0; i < 2 && end+3 < len(p.src) && p.src[end+3] == '\''; i++ {
+		end++
+	}
+
+	s := p.src[p.pos:end]
+	p.line += strings.Count(s, "\n")
+	p.pos = end + 3
+	return strings.ReplaceAll(s, "\r\n", "\n"), nil
+}
+
+// scalar parses a boolean, number, date or time
+func (p *tomlParser) scalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	// A space may separate the date and time of a date time
+	if isDate(p.src[start:p.pos]) && p.pos+3 < len(p.src) && p.src[p.pos] == ' ' &&
+		isDigit(p.src[p.pos+1]) && isDigit(p.src[p.pos+2]) && p.src[p.pos+3] == ':' {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+			p.pos++
+		}
+	}
+
+	token := p.src[start:p.pos]
+	if token == "" {
+		if p.eof() || p.peek() == '\n' || p.peek() == '\r' {
+			return nil, p.errorf("expected a value")
+		}
+		return nil, p.errorf("unexpected %q, expected a value", p.peek())
+	}
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if isDate(token) || len(token) > 10 && isDate(token[:10]) {
+		return p.dateTime(token)
+	}
+	if len(token) >= 8 && isDigit(token[0]) && isDigit(token[1]) && token[2] == ':' {
+		if _, err := time.Parse("15:04:05.999999999", token); err != nil {
+			return nil, p.errorf("invalid time %q", token)
+		}
+		return token, nil
+	}
+
+	return p.number(token)
+}
+
+// dateTime parses an offset or local date time, or a date
+func (p *tomlParser) dateTime(token string) (time.Time, error) {
+	if len(token) > 10 {
+		// RFC 3339 allows t or a space instead of T
+		token = token[:10] + "T" + token[11:]
+		token = strings.ToUpper(token)
+	}
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	} {
+		if t, err := time.Parse(layout, token); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, p.errorf("invalid date %q", token)
+}
+
+// number parses an integer or float, with optional _ separators
+func (p *tomlParser) number(token string) (interface{}, error) {
+	digits := strings.TrimLeft(token, "+-")
+	if digits == "" || !isDigit(digits[0]) {
+		return nil, p.errorf("invalid value %q", token)
+	}
+	clean := strings.ReplaceAll(token, "_", "")
+	prefixed := len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("xob", rune(digits[1]))
+
+	// Underscores must sit between digits
+	for i := 0; i < len(token); i++ {
+		if token[i] == '_' && (i == 0 || i+1 == len(token) || !isNumberDigit(token[i-1], prefixed) || !isNumberDigit(token[i+1], prefixed)) {
+			return nil, p.errorf("invalid number %q", token)
+		}
+	}
+
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(clean, prefix) {
+			n, err := strconv.ParseInt(clean[2:], base, 64)
+			if err != nil {
+				return nil, p.errorf("invalid number %q", token)
+			}
+			return n, nil
+		}
+	}
+
+	// Decimal numbers have no leading zeros, and a decimal point needs
+	// digits on both sides
+	mantissa := strings.TrimLeft(clean, "+-")
+	if len(mantissa) > 1 && mantissa[0] == '0' && isDigit(mantissa[1]) {
+		return nil, p.errorf("invalid number %q: leading zeros are not allowed", token)
+	}
+	if i := strings.IndexByte(clean, '.'); i >= 0 && (!isDigit(clean[i-1]) || i+1 == len(clean) || !isDigit(clean[i+1])) {
+		return nil, p.errorf("invalid number %q", token)
+	}
+
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil && !strings.ContainsAny(clean, "xXpP") {
+		return f, nil
+	}
+	return nil, p.errorf("invalid value %q", token)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isNumberDigit reports whether c is a digit of a number, which is any hex
+// digit for numbers with a base prefix such as 0x
+func isNumberDigit(c byte, prefixed bool) bool {
+	return isDigit(c) || prefixed && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F')
+}
+
+// isDate reports whether s has the form YYYY-MM-DD
+func isDate(s string) bool {
+	if len(s) != 10 || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+	for _, i := range []int{0, 1, 2, 3, 5, 6, 8, 9} {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package data
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTOML(t *testing.T) {
+	src := `# Product specs
+name = "Widget"
+"quoted key" = 'C:\path'
+price = 9.99
+stock = 1_000
+mask = 0xff
+released = 1979-05-27T07:32:00Z
+launch = 2024-03-01
+opens = 09:30:00
+enabled = true
+sizes = [
+  "S",
+  "M", # comment
+  "L",
+]
+dimensions = { width = 10, height.cm = 20 }
+site.url = "https://example.com"
+notes = """
+Line one \
+  continued
+Line "two\""""
+raw = '''
+C:\raw'''
+
+[owner]
+name = "Tom"
+
+[owner.address]
+city = "Berlin"
+
+[[members]]
+name = "Ada"
+
+[[members]]
+name = "Linus"
+roles = ["kernel"]
+`
+
+	got, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("parseTOML failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"name":       "Widget",
+		"quoted key": `C:\path`,
+		"price":      9.99,
+		"stock":      int64(1000),
+		"mask":       int64(255),
+		"released":   time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"launch":     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"opens":      "09:30:00",
+		"enabled":    true,
+		"sizes":      []interface{}{"S", "M", "L"},
+		"dimensions": map[string]interface{}{
+			"width":  int64(10),
+			"height": map[string]interface{}{"cm": int64(20)},
+		},
+		"site":  map[string]interface{}{"url": "https://example.com"},
+		"notes": "Line one continued\nLine \"two\"",
+		"raw":   `C:\raw`,
+		"owner": map[string]interface{}{
+			"name":    "Tom",
+			"address": map[string]interface{}{"city": "Berlin"},
+		},
+		"members": []interface{}{
+			map[string]interface{}{"name": "Ada"},
+			map[string]interface{}{"name": "Linus", "roles": []interface{}{"kernel"}},
+		},
+	}
+
+	for key, value := range want {
+		if released, ok := value.(time.Time); ok {
+			if got, ok := got[key].(time.Time); !ok || !got.Equal(released) {
+				t.Errorf("Expected %s to be %v, got %v", key, released, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[key], value) {
+			t.Errorf("Expected %s to be %#v, got %#v", key, value, got[key])
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected %d keys, got %d", len(want), len(got))
+	}
+}
+
+func TestParseTOMLSpecialFloats(t *testing.T) {
+	got, err := parseTOML([]byte("a = inf\nb = -inf\nc = nan\nd = 6.626e-34\n"))
+	if err != nil {
+		t.Fatalf("parseTOML failed: %v", err)
+	}
+	if !math.IsInf(got["a"].(float64), 1) || !math.IsInf(got["b"].(float64), -1) {
+		t.Errorf("Expected infinities, got %v and %v", got["a"], got["b"])
+	}
+	if !math.IsNaN(got["c"].(float64)) {
+		t.Errorf("Expected NaN, got %v", got["c"])
+	}
+	if got["d"] != 6.626e-34 {
+		t.Errorf("Expected 6.626e-34, got %v", got["d"])
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"duplicate key", "a = 1\na = 2", "line 2: duplicate key"},
+		{"missing value", "a =\n", "line 1: expected a value"},
+		{"missing equals", "a 1", "line 1: expected ="},
+		{"unterminated string", "a = \"abc\nb = 1", "line 1: unterminated string"},
+		{"unterminated array", "a = [1, 2", "unterminated array"},
+		{"invalid value", "a = maybe", "line 1: invalid value"},
+		{"trailing text", "a = 1 2", "line 1: unexpected"},
+		{"table over value", "a = 1\n[a]", "line 2: \"a\" is already defined"},
+		{"invalid escape", `a = "\q"`, "invalid escape"},
+		{"invalid number", "a = 1__000", "line 1: invalid number"},
+		{"underscore before point", "a = 1_.5", "line 1: invalid number"},
+		{"leading zeros", "a = 007", "line 1: invalid number \"007\": leading zeros"},
+		{"trailing point", "a = 1.", "line 1: invalid number"},
+		{"point before exponent", "a = 1.e5", "line 1: invalid number"},
+		{"table defined twice", "[a]\nb = 1\n[a]\nc = 2", "line 3: table \"a\" is already defined"},
+		{"table after dotted keys", "a.b = 1\n[a]", "line 2: table \"a\" is already defined"},
+		{"dotted keys into a table", "[a.b]\nc = 1\n[a]\nb.d = 2", "line 4: table \"b\" is already defined"},
+		{"inline table extended", "a = { b = 1 }\n[a.c]", "line 2: \"a\" is an inline table"},
+		{"inline table dotted", "a = { b = 1 }\na.c = 2", "line 2: table \"a\" is already defined"},
+		{"array of tables over array", "a = []\n[[a]]", "line 2: \"a\" is already defined and is not an array of tables"},
+		{"table in static array", "a = [{ b = 1 }]\n[a.c]", "line 2: \"a\" is already defined and is not a table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/dikaio/scribe/internal/data"
+)
+
+// Translation is a translated string. Strings that depend on a count have
+// a form for a count of one and a form for other counts.
+type Translation struct {
+	One   string
+	Other string
+}
+
+// Bundle holds the translated strings of a site by language
+type Bundle struct {
+	defaultLang string
+	tables      map[string]map[string]Translation
+}
+
+// Load reads the string tables in dirs, one file per language such as
+// i18n/de.yml. Strings in later directories take precedence, so pass the
+// theme's directory before the site's. Missing directories are skipped.
+// Strings missing in a language fall back to defaultLang.
+func Load(defaultLang string, dirs ...string) (*Bundle, error) {
+	b := &Bundle{
+		defaultLang: defaultLang,
+		tables:      make(map[string]map[string]Translation),
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			switch ext {
+			case ".yml", ".yaml", ".json", ".toml":
+			default:
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			value, err := data.Unmarshal(src, ext)
+			if err != nil {
+				return nil, fmt.Errorf("error reading translations %s: %w", path, err)
+			}
+			table, err := parseTable(value)
+			if err != nil {
+				return nil, fmt.Errorf("error reading translations %s: %w", path, err)
+			}
+
+			lang := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			if b.tables[lang] == nil {
+				b.tables[lang] = make(map[string]Translation)
+			}
+			for id, translation := range table {
+				b.tables[lang][id] = translation
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// parseTable reads a string table: a map from IDs to strings, or a list
+// of entries with an id and a translation. A translation with a count is
+// a map with "one" and "other" forms.
+func parseTable(value interface{}) (map[string]Translation, error) {
+	table := make(map[string]Translation)
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for id, raw := range v {
+			translation, err := parseTranslation(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", id, err)
+			}
+			table[id] = translation
+		}
+	case []interface{}:
+		for i, item := range v {
+			entry, ok := item.(map[string]interface{})
+			id, _ := entry["id"].(string)
+			if !ok || id == "" {
+				return nil, fmt.Errorf("entry %d has no id", i+1)
+			}
+			translation, err := parseTranslation(entry["translation"])
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", id, err)
+			}
+			table[id] = translation
+		}
+	default:
+		return nil, fmt.Errorf("expected a map of translations")
+	}
+	return table, nil
+}
+
+// parseTranslation reads a string, or a map of plural forms
+func parseTranslation(raw interface{}) (Translation, error) {
+	switch v := raw.(type) {
+	case string:
+		return Translation{Other: v}, nil
+	case map[string]interface{}:
+		one, _ := v["one"].(string)
+		other, _ := v["other"].(string)
+		if one == "" && other == "" {
+			return Translation{}, fmt.Errorf("expected one and other forms")
+		}
+		return Translation{One: one, Other: other}, nil
+	}
+	return Translation{}, fmt.Errorf("expected a string, got %v", raw)
+}
+
+// Translate returns the string id in lang, falling back to the default
+// language and then to id itself. The string is a template executed with
+// arg; a number selects the plural form and is available as {{ .Count }}.
+func (b *Bundle) Translate(lang, id string, arg interface{}) (string, error) {
+	translation, ok := b.tables[lang][id]
+	if !ok {
+		translation, ok = b.tables[b.defaultLang][id]
+	}
+	if !ok {
+		return id, nil
+	}
+
+	count, isCount := toCount(arg)
+	text := translation.Other
+	if (isCount && count == 1 && translation.One != "") || text == "" {
+		text = translation.One
+	}
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(id).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("translation %q: %w", id, err)
+	}
+	if isCount {
+		arg = map[string]interface{}{"Count": arg}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, arg); err != nil {
+		return "", fmt.Errorf("translation %q: %w", id, err)
+	}
+	return buf.String(), nil
+}
+
+// toCount returns the value of a number
+func toCount(arg interface{}) (float64, bool) {
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"themes/default/i18n/en.yml": "readMore: Read on\nposts:\n  one: One post\n  other: \"{{ .Count }} posts\"\n",
+		"i18n/en.yml":                "readMore: Read more\n",
+		"i18n/de.json":               `[{"id": "readMore", "translation": "Weiterlesen"}, {"id": "greeting", "translation": "Hallo {{ .Name }}"}]`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	bundle, err := Load("en", filepath.Join(dir, "themes/default/i18n"), filepath.Join(dir, "i18n"), filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		lang string
+		id   string
+		arg  interface{}
+		want string
+	}{
+		{"en", "readMore", nil, "Read more"},
+		{"de", "readMore", nil, "Weiterlesen"},
+		{"de", "greeting", map[string]string{"Name": "Ada"}, "Hallo Ada"},
+		{"en", "posts", 1, "One post"},
+		{"en", "posts", 3, "3 posts"},
+		{"de", "posts", 2, "2 posts"},
+		{"de", "unknown", nil, "unknown"},
+	}
+	for _, tt := range tests {
+		got, err := bundle.Translate(tt.lang, tt.id, tt.arg)
+		if err != nil {
+			t.Errorf("Translate(%s, %s) failed: %v", tt.lang, tt.id, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Translate(%s, %s): expected %q, got %q", tt.lang, tt.id, tt.want, got)
+		}
+	}
+}
+
+func TestLoadReportsBadTables(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "de.yml"), []byte("- translation: Hallo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write de.yml: %v", err)
+	}
+	if _, err := Load("en", dir); err == nil || !strings.Contains(err.Error(), "entry 1 has no id") {
+		t.Errorf("Expected an error about the missing id, got %v", err)
+	}
+}
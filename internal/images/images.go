@@ -0,0 +1,291 @@
+// Package images resizes, crops and converts images using only the
+// standard library decoders and encoders (JPEG, PNG and GIF).
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Operations supported by Process
+const (
+	OpResize = "resize"
+	OpFit    = "fit"
+	OpFill   = "fill"
+)
+
+// DefaultQuality is the JPEG quality used when none is specified
+const DefaultQuality = 75
+
+// Anchor selects which part of an image is kept when cropping
+type Anchor string
+
+// Supported anchors
+const (
+	AnchorCenter      Anchor = "center"
+	AnchorTop         Anchor = "top"
+	AnchorBottom      Anchor = "bottom"
+	AnchorLeft        Anchor = "left"
+	AnchorRight       Anchor = "right"
+	AnchorTopLeft     Anchor = "topleft"
+	AnchorTopRight    Anchor = "topright"
+	AnchorBottomLeft  Anchor = "bottomleft"
+	AnchorBottomRight Anchor = "bottomright"
+)
+
+// Spec describes a processing request such as "800x600 center q80 png"
+type Spec struct {
+	Width   int
+	Height  int
+	Anchor  Anchor
+	Format  string
+	Quality int
+}
+
+// ParseSpec parses a space separated processing specification.
+// Dimensions are written as "800x600", "800x" or "x600".
+func ParseSpec(s string) (Spec, error) {
+	var spec Spec
+
+	for _, token := range strings.Fields(strings.ToLower(s)) {
+		switch {
+		case strings.Contains(token, "x") && isDimension(token):
+			parts := strings.SplitN(token, "x", 2)
+			if parts[0] != "" {
+				spec.Width, _ = strconv.Atoi(parts[0])
+			}
+			if parts[1] != "" {
+				spec.Height, _ = strconv.Atoi(parts[1])
+			}
+
+		case len(token) > 1 && token[0] == 'q' && isDigits(token[1:]):
+			spec.Quality, _ = strconv.Atoi(token[1:])
+			if spec.Quality < 1 || spec.Quality > 100 {
+				return spec, fmt.Errorf("invalid quality %q: must be between 1 and 100", token)
+			}
+
+		case isAnchor(token):
+			spec.Anchor = Anchor(token)
+
+		case token == "jpg" || token == "jpeg" || token == "png" || token == "gif":
+			spec.Format = normalizeFormat(token)
+
+		default:
+			return spec, fmt.Errorf("invalid image spec %q: unknown option %q", s, token)
+		}
+	}
+
+	if spec.Width == 0 && spec.Height == 0 {
+		return spec, fmt.Errorf("invalid image spec %q: missing dimensions", s)
+	}
+
+	return spec, nil
+}
+
+// String returns the canonical form of the spec, suitable for cache keys
+func (s Spec) String() string {
+	parts := []string{fmt.Sprintf("%dx%d", s.Width, s.Height)}
+	if s.Anchor != "" {
+		parts = append(parts, string(s.Anchor))
+	}
+	if s.Format != "" {
+		parts = append(parts, s.Format)
+	}
+	if s.Quality != 0 {
+		parts = append(parts, "q"+strconv.Itoa(s.Quality))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Process applies an operation to img
+func Process(img image.Image, op string, spec Spec) (image.Image, error) {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return nil, fmt.Errorf("cannot process an empty image")
+	}
+
+	switch op {
+	case OpResize:
+		w, h := spec.Width, spec.Height
+		if w == 0 {
+			w = scaleDimension(srcW, h, srcH)
+		}
+		if h == 0 {
+			h = scaleDimension(srcH, w, srcW)
+		}
+		return resample(img, w, h), nil
+
+	case OpFit:
+		if spec.Width == 0 || spec.Height == 0 {
+			return nil, fmt.Errorf("fit requires both width and height")
+		}
+		// Scale down to fit inside the box, never up
+		ratio := math.Min(float64(spec.Width)/float64(srcW), float64(spec.Height)/float64(srcH))
+		if ratio >= 1 {
+			return img, nil
+		}
+		w := int(math.Round(float64(srcW) * ratio))
+		h := int(math.Round(float64(srcH) * ratio))
+		return resample(img, max(w, 1), max(h, 1)), nil
+
+	case OpFill:
+		if spec.Width == 0 || spec.Height == 0 {
+			return nil, fmt.Errorf("fill requires both width and height")
+		}
+		// Crop to the target aspect ratio first, then scale
+		cropRect := cropRectangle(bounds, spec.Width, spec.Height, spec.Anchor)
+		return resample(crop(img, cropRect), spec.Width, spec.Height), nil
+
+	default:
+		return nil, fmt.Errorf("unknown image operation %q", op)
+	}
+}
+
+// cropRectangle returns the largest rectangle of bounds with the aspect
+// ratio width:height, positioned by anchor
+func cropRectangle(bounds image.Rectangle, width, height int, anchor Anchor) image.Rectangle {
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	targetRatio := float64(width) / float64(height)
+
+	cropW, cropH := srcW, srcH
+	if float64(srcW)/float64(srcH) > targetRatio {
+		cropW = int(math.Round(float64(srcH) * targetRatio))
+	} else {
+		cropH = int(math.Round(float64(srcW) / targetRatio))
+	}
+
+	// Center by default
+	x := (srcW - cropW) / 2
+	y := (srcH - cropH) / 2
+
+	a := string(anchor)
+	if strings.Contains(a, "left") {
+		x = 0
+	}
+	if strings.Contains(a, "right") {
+		x = srcW - cropW
+	}
+	if strings.HasPrefix(a, "top") {
+		y = 0
+	}
+	if strings.HasPrefix(a, "bottom") {
+		y = srcH - cropH
+	}
+
+	origin := bounds.Min.Add(image.Pt(x, y))
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(cropW, cropH))}
+}
+
+// Decode decodes an image and reports its format ("jpeg", "png" or "gif")
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, format, nil
+}
+
+// DecodeConfig returns the dimensions and format of an image without
+// decoding the pixel data
+func DecodeConfig(data []byte) (int, int, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to read image header: %w", err)
+	}
+	return cfg.Width, cfg.Height, format, nil
+}
+
+// Encode encodes img in the given format
+func Encode(img image.Image, format string, quality int) ([]byte, error) {
+	if quality == 0 {
+		quality = DefaultQuality
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch normalizeFormat(format) {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		return nil, fmt.Errorf("unsupported image format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Extension returns the file extension used for a format
+func Extension(format string) string {
+	if normalizeFormat(format) == "jpeg" {
+		return ".jpg"
+	}
+	return "." + normalizeFormat(format)
+}
+
+// IsImage reports whether a file extension is a supported image type
+func IsImage(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeFormat maps format aliases to the names used by image.Decode
+func normalizeFormat(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "jpg" {
+		return "jpeg"
+	}
+	return format
+}
+
+// scaleDimension scales other by target/reference, keeping aspect ratio
+func scaleDimension(other, target, reference int) int {
+	return max(int(math.Round(float64(other)*float64(target)/float64(reference))), 1)
+}
+
+// isDimension reports whether token looks like "800x600", "800x" or "x600"
+func isDimension(token string) bool {
+	parts := strings.SplitN(token, "x", 2)
+	return len(parts) == 2 && (parts[0] != "" || parts[1] != "") &&
+		(parts[0] == "" || isDigits(parts[0])) && (parts[1] == "" || isDigits(parts[1]))
+}
+
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAnchor reports whether token names an anchor
+func isAnchor(token string) bool {
+	switch Anchor(token) {
+	case AnchorCenter, AnchorTop, AnchorBottom, AnchorLeft, AnchorRight,
+		AnchorTopLeft, AnchorTopRight, AnchorBottomLeft, AnchorBottomRight:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testImage creates a solid image of the given size
+func testImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	return img
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Spec
+		wantErr  bool
+	}{
+		{input: "800x", expected: Spec{Width: 800}},
+		{input: "x600", expected: Spec{Height: 600}},
+		{input: "400x300 center", expected: Spec{Width: 400, Height: 300, Anchor: AnchorCenter}},
+		{input: "400x300 TopLeft q80 jpg", expected: Spec{Width: 400, Height: 300, Anchor: AnchorTopLeft, Quality: 80, Format: "jpeg"}},
+		{input: "center", wantErr: true},
+		{input: "800x q0", wantErr: true},
+		{input: "800x sideways", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			spec, err := ParseSpec(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSpec failed: %v", err)
+			}
+			if spec != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, spec)
+			}
+		})
+	}
+}
+
+func TestProcess(t *testing.T) {
+	src := testImage(200, 100)
+
+	tests := []struct {
+		op     string
+		spec   string
+		width  int
+		height int
+	}{
+		{op: OpResize, spec: "100x", width: 100, height: 50},
+		{op: OpResize, spec: "x25", width: 50, height: 25},
+		{op: OpResize, spec: "50x50", width: 50, height: 50},
+		{op: OpFit, spec: "50x50", width: 50, height: 25},
+		{op: OpFit, spec: "400x400", width: 200, height: 100},
+		{op: OpFill, spec: "50x50", width: 50, height: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op+" "+tt.spec, func(t *testing.T) {
+			spec, err := ParseSpec(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSpec failed: %v", err)
+			}
+			img, err := Process(src, tt.op, spec)
+			if err != nil {
+				t.Fatalf("Process failed: %v", err)
+			}
+			if img.Bounds().Dx() != tt.width || img.Bounds().Dy() != tt.height {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.width, tt.height, img.Bounds().Dx(), img.Bounds().Dy())
+			}
+		})
+	}
+
+	// Solid colors survive resampling
+	img, _ := Process(src, OpResize, Spec{Width: 37})
+	r, g, b, _ := img.At(10, 5).RGBA()
+	if r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+		t.Errorf("Expected color to be preserved, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestCropRectangle(t *testing.T) {
+	bounds := image.Rect(0, 0, 200, 100)
+
+	tests := map[Anchor]image.Rectangle{
+		AnchorCenter:      image.Rect(50, 0, 150, 100),
+		AnchorLeft:        image.Rect(0, 0, 100, 100),
+		AnchorRight:       image.Rect(100, 0, 200, 100),
+		AnchorBottomRight: image.Rect(100, 0, 200, 100),
+	}
+	for anchor, expected := range tests {
+		if r := cropRectangle(bounds, 50, 50, anchor); r != expected {
+			t.Errorf("cropRectangle(%s) = %v, want %v", anchor, r, expected)
+		}
+	}
+
+	// Tall crops are positioned vertically
+	if r := cropRectangle(bounds, 200, 50, AnchorTop); r != image.Rect(0, 0, 200, 50) {
+		t.Errorf("Unexpected top crop %v", r)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, format := range []string{"jpg", "png", "gif"} {
+		data, err := Encode(testImage(20, 10), format, 0)
+		if err != nil {
+			t.Fatalf("Encode(%s) failed: %v", format, err)
+		}
+		w, h, decoded, err := DecodeConfig(data)
+		if err != nil {
+			t.Fatalf("DecodeConfig(%s) failed: %v", format, err)
+		}
+		if w != 20 || h != 10 || decoded != normalizeFormat(format) {
+			t.Errorf("Expected 20x10 %s, got %dx%d %s", format, w, h, decoded)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package images
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// catmullRom is the Catmull-Rom cubic filter (B=0, C=0.5), a good balance
+// of sharpness and smoothness for photographs
+func catmullRom(x float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return (1.5*x-2.5)*x*x + 1
+	case x < 2:
+		return ((-0.5*x+2.5)*x-4)*x + 2
+	default:
+		return 0
+	}
+}
+
+// filterSupport is the radius of the catmullRom filter
+const filterSupport = 2.0
+
+// contribution holds the source pixels and weights for one output pixel
+type contribution struct {
+	start   int
+	weights []float64
+}
+
+// computeContributions precomputes filter weights for scaling srcSize
+// pixels to dstSize pixels along one axis
+func computeContributions(srcSize, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+
+	// Widen the filter when downsampling so every source pixel contributes
+	filterScale := math.Max(scale, 1)
+	support := filterSupport * filterScale
+
+	contribs := make([]contribution, dstSize)
+	for x := 0; x < dstSize; x++ {
+		center := (float64(x)+0.5)*scale - 0.5
+		start := int(math.Floor(center - support))
+		end := int(math.Ceil(center + support))
+
+		weights := make([]float64, 0, end-start+1)
+		sum := 0.0
+		for i := start; i <= end; i++ {
+			w := catmullRom((float64(i) - center) / filterScale)
+			weights = append(weights, w)
+			sum += w
+		}
+		if sum != 0 {
+			for i := range weights {
+				weights[i] /= sum
+			}
+		}
+		contribs[x] = contribution{start: start, weights: weights}
+	}
+	return contribs
+}
+
+// resample scales src to width x height
+func resample(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	// Work on premultiplied RGBA so transparent pixels don't bleed color
+	rgba := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	// Horizontal pass: srcW x srcH -> width x srcH
+	tmp := make([]float64, width*srcH*4)
+	xContribs := computeContributions(srcW, width)
+	parallelRows(srcH, func(y int) {
+		row := rgba.Pix[y*rgba.Stride:]
+		for x, c := range xContribs {
+			var r, g, b, a float64
+			for i, w := range c.weights {
+				sx := clamp(c.start+i, 0, srcW-1) * 4
+				r += w * float64(row[sx])
+				g += w * float64(row[sx+1])
+				b += w * float64(row[sx+2])
+				a += w * float64(row[sx+3])
+			}
+			off := (y*width + x) * 4
+			tmp[off], tmp[off+1], tmp[off+2], tmp[off+3] = r, g, b, a
+		}
+	})
+
+	// Vertical pass: width x srcH -> width x height
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	yContribs := computeContributions(srcH, height)
+	parallelRows(height, func(y int) {
+		c := yContribs[y]
+		for x := 0; x < width; x++ {
+			var r, g, b, a float64
+			for i, w := range c.weights {
+				off := (clamp(c.start+i, 0, srcH-1)*width + x) * 4
+				r += w * tmp[off]
+				g += w * tmp[off+1]
+				b += w * tmp[off+2]
+				a += w * tmp[off+3]
+			}
+
+			// Convert back from premultiplied alpha
+			off := y*dst.Stride + x*4
+			alpha := clampFloat(a)
+			if alpha == 0 {
+				dst.Pix[off], dst.Pix[off+1], dst.Pix[off+2], dst.Pix[off+3] = 0, 0, 0, 0
+				continue
+			}
+			factor := 255 / float64(alpha)
+			dst.Pix[off] = clampFloat(r * factor)
+			dst.Pix[off+1] = clampFloat(g * factor)
+			dst.Pix[off+2] = clampFloat(b * factor)
+			dst.Pix[off+3] = alpha
+		}
+	})
+
+	return dst
+}
+
+// crop returns the rectangle r of img as a new image
+func crop(img image.Image, r image.Rectangle) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+// parallelRows calls fn for every row in [0, rows) using all CPUs
+func parallelRows(rows int, fn func(y int)) {
+	workers := runtime.NumCPU()
+	if workers > rows {
+		workers = rows
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for y := w; y < rows; y += workers {
+				fn(y)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// clamp limits v to [lo, hi]
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// clampFloat rounds v to the nearest byte value
+func clampFloat(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
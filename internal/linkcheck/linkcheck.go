@@ -0,0 +1,419 @@
+// Package linkcheck finds broken links in a built site by resolving every
+// href and src in the generated HTML against the output directory.
+package linkcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// tagRe matches opening tags of elements that reference other documents
+	tagRe = regexp.MustCompile(`(?is)<(a|area|link|img|script|source|iframe|video|audio|embed)\b([^>]*)>`)
+
+	// attrRe matches an attribute with a quoted or unquoted value
+	attrRe = regexp.MustCompile(`(?is)([a-z][a-z0-9_:-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+	// anchorRe matches id attributes and <a name=...> anchors
+	anchorRe = regexp.MustCompile(`(?is)<[a-z][a-z0-9]*\b[^>]*?\s(?:id|name)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+	// ignoredRe matches comments and script/style bodies, which aren't links
+	ignoredRe = regexp.MustCompile(`(?is)<!--.*?-->|(<script\b[^>]*>).*?</script>|(<style\b[^>]*>).*?</style>`)
+)
+
+// ExternalMode selects how links to other sites are handled
+type ExternalMode int
+
+const (
+	// ExternalSkip ignores external links
+	ExternalSkip ExternalMode = iota
+	// ExternalCheck requests external links over the network
+	ExternalCheck
+)
+
+// Options configures a link check
+type Options struct {
+	// BaseURL of the site; absolute links under it are checked locally
+	BaseURL string
+	// External selects how links to other sites are handled
+	External ExternalMode
+	// StandIn, when set, replaces the scheme and host of external links,
+	// so they are checked against a local server instead of the internet
+	StandIn string
+	// Client performs external requests (http.DefaultClient with a
+	// timeout when nil)
+	Client *http.Client
+}
+
+// BrokenLink is a link that doesn't resolve
+type BrokenLink struct {
+	URL    string
+	Reason string
+}
+
+// Report lists the broken links found, grouped by source page
+type Report struct {
+	Pages  int
+	Links  int
+	Broken map[string][]BrokenLink
+}
+
+// Count returns the number of broken links
+func (r *Report) Count() int {
+	count := 0
+	for _, links := range r.Broken {
+		count += len(links)
+	}
+	return count
+}
+
+// Write prints the broken links grouped by source page
+func (r *Report) Write(w io.Writer) {
+	pages := make([]string, 0, len(r.Broken))
+	for page := range r.Broken {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+
+	for _, page := range pages {
+		fmt.Fprintf(w, "%s\n", page)
+		for _, link := range r.Broken[page] {
+			fmt.Fprintf(w, "  %s (%s)\n", link.URL, link.Reason)
+		}
+	}
+	fmt.Fprintf(w, "Checked %d links in %d pages: %d broken\n", r.Links, r.Pages, r.Count())
+}
+
+// Checker checks the links of a built site
+type Checker struct {
+	outputPath string
+	options    Options
+	basePath   string
+	baseHost   string
+
+	mu       sync.Mutex
+	anchors  map[string]map[string]bool
+	external map[string]string
+}
+
+// NewChecker creates a checker for the site built into outputPath
+func NewChecker(outputPath string, options Options) *Checker {
+	c := &Checker{
+		outputPath: outputPath,
+		options:    options,
+		basePath:   "/",
+		anchors:    make(map[string]map[string]bool),
+		external:   make(map[string]string),
+	}
+
+	if u, err := url.Parse(options.BaseURL); err == nil && u.Host != "" {
+		c.baseHost = strings.ToLower(u.Host)
+		c.basePath = "/" + strings.Trim(u.Path, "/")
+		if c.basePath != "/" {
+			c.basePath += "/"
+		}
+	}
+	if c.options.Client == nil {
+		c.options.Client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	return c
+}
+
+// link is a reference found in a page
+type link struct {
+	page string
+	url  string
+}
+
+// Check parses every HTML file in the output directory and reports links
+// that don't resolve
+func (c *Checker) Check() (*Report, error) {
+	if info, err := os.Stat(c.outputPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("output directory %s not found, build the site first", c.outputPath)
+	}
+
+	var files []string
+	err := filepath.Walk(c.outputPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isHTML(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	report := &Report{Broken: make(map[string][]BrokenLink)}
+	var externalLinks []link
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		page := c.pageURL(file)
+		report.Pages++
+
+		for _, ref := range extractLinks(string(data)) {
+			report.Links++
+
+			if isExternal(ref, c.baseHost) {
+				if c.options.External == ExternalCheck {
+					externalLinks = append(externalLinks, link{page: page, url: ref})
+				}
+				continue
+			}
+
+			if reason := c.checkInternal(page, ref); reason != "" {
+				report.Broken[page] = append(report.Broken[page], BrokenLink{URL: ref, Reason: reason})
+			}
+		}
+	}
+
+	// External links are requested concurrently, each URL once
+	c.checkExternal(externalLinks)
+	for _, l := range externalLinks {
+		if reason := c.external[l.url]; reason != "" {
+			report.Broken[l.page] = append(report.Broken[l.page], BrokenLink{URL: l.url, Reason: reason})
+		}
+	}
+
+	return report, nil
+}
+
+// pageURL returns the root-relative URL a file is served at
+func (c *Checker) pageURL(file string) string {
+	rel, err := filepath.Rel(c.outputPath, file)
+	if err != nil {
+		return file
+	}
+	rel = filepath.ToSlash(rel)
+	if path.Base(rel) == "index.html" {
+		return "/" + strings.TrimSuffix(rel, "index.html")
+	}
+	return "/" + rel
+}
+
+// checkInternal resolves a link within the site, returning why it's
+// broken or "" if it resolves
+func (c *Checker) checkInternal(page, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "invalid URL"
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		// mailto:, tel:, data: and friends
+		return ""
+	}
+
+	// Resolve against the page, then map the URL onto the output tree
+	target := u.Path
+	if u.Host == "" && !strings.HasPrefix(target, "/") {
+		if target == "" {
+			target = page
+		} else {
+			target = path.Join(path.Dir(page+"x"), target)
+			if strings.HasSuffix(u.Path, "/") {
+				target += "/"
+			}
+		}
+	} else if c.basePath != "/" {
+		if !strings.HasPrefix(target+"/", c.basePath) {
+			return fmt.Sprintf("outside the base path %s", c.basePath)
+		}
+		target = "/" + strings.TrimPrefix(target, c.basePath)
+	}
+
+	file, ok := c.resolveFile(target)
+	if !ok {
+		return "not found"
+	}
+
+	if u.Fragment == "" || !isHTML(file) {
+		return ""
+	}
+	anchors, err := c.fileAnchors(file)
+	if err != nil {
+		return err.Error()
+	}
+	if !anchors[u.Fragment] {
+		return fmt.Sprintf("anchor #%s not found", u.Fragment)
+	}
+	return ""
+}
+
+// resolveFile finds the output file served for a root-relative URL path
+func (c *Checker) resolveFile(urlPath string) (string, bool) {
+	if decoded, err := url.PathUnescape(urlPath); err == nil {
+		urlPath = decoded
+	}
+	file := filepath.Join(c.outputPath, filepath.FromSlash(path.Clean("/"+urlPath)))
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", false
+	}
+	if !info.IsDir() {
+		return file, true
+	}
+	index := filepath.Join(file, "index.html")
+	if _, err := os.Stat(index); err != nil {
+		return "", false
+	}
+	return index, true
+}
+
+// fileAnchors returns the anchors defined in an HTML file
+func (c *Checker) fileAnchors(file string) (map[string]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if anchors, ok := c.anchors[file]; ok {
+		return anchors, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	anchors := make(map[string]bool)
+	text := ignoredRe.ReplaceAllString(string(data), "$1$2")
+	for _, m := range anchorRe.FindAllStringSubmatch(text, -1) {
+		anchors[m[1]+m[2]+m[3]] = true
+	}
+	c.anchors[file] = anchors
+	return anchors, nil
+}
+
+// checkExternal requests each distinct external URL and records failures
+// in c.external
+func (c *Checker) checkExternal(links []link) {
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ref := range jobs {
+				reason := c.fetch(ref)
+				c.mu.Lock()
+				c.external[ref] = reason
+				c.mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range links {
+		if !seen[l.url] {
+			seen[l.url] = true
+			jobs <- l.url
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// fetch requests an external URL, returning why it failed or ""
+func (c *Checker) fetch(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "invalid URL"
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+	u.Fragment = ""
+
+	if c.options.StandIn != "" {
+		standIn, err := url.Parse(c.options.StandIn)
+		if err != nil {
+			return "invalid stand-in URL"
+		}
+		u.Scheme, u.Host = standIn.Scheme, standIn.Host
+	}
+
+	// Some servers don't support HEAD, so fall back to GET
+	resp, err := c.options.Client.Head(u.String())
+	if err == nil && resp.StatusCode >= 400 {
+		resp.Body.Close()
+		resp, err = c.options.Client.Get(u.String())
+	}
+	if err != nil {
+		return "request failed: " + err.Error()
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return resp.Status
+	}
+	return ""
+}
+
+// extractLinks returns the URLs referenced by href, src and srcset
+// attributes in an HTML document
+func extractLinks(html string) []string {
+	html = ignoredRe.ReplaceAllString(html, "$1$2")
+
+	var links []string
+	for _, tag := range tagRe.FindAllStringSubmatch(html, -1) {
+		for _, attr := range attrRe.FindAllStringSubmatch(tag[2], -1) {
+			value := strings.TrimSpace(attr[2] + attr[3] + attr[4])
+			switch strings.ToLower(attr[1]) {
+			case "href", "src":
+				if value != "" {
+					links = append(links, unescapeHTML(value))
+				}
+			case "srcset":
+				for _, candidate := range strings.Split(value, ",") {
+					fields := strings.Fields(candidate)
+					if len(fields) > 0 {
+						links = append(links, unescapeHTML(fields[0]))
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// isExternal reports whether a link points to another site
+func isExternal(ref, baseHost string) bool {
+	lower := strings.ToLower(ref)
+	if !strings.HasPrefix(lower, "http:") && !strings.HasPrefix(lower, "https:") && !strings.HasPrefix(lower, "//") {
+		return false
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return true
+	}
+	return strings.ToLower(u.Host) != baseHost
+}
+
+// isHTML reports whether a file is an HTML document
+func isHTML(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".html" || ext == ".htm"
+}
+
+// unescapeHTML decodes the entities that commonly appear in URLs
+func unescapeHTML(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&#38;", "&", "&#34;", `"`, "&quot;", `"`, "&#39;", "'").Replace(s)
+}
@@ -0,0 +1,137 @@
+package linkcheck
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSite writes the given files into a temporary output directory
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	outputPath := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(outputPath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return outputPath
+}
+
+func TestCheck(t *testing.T) {
+	outputPath := writeSite(t, map[string]string{
+		"index.html": `<a href="/about/">About</a>
+<a href="about/#team">Team</a>
+<a href="/about/#missing">Missing anchor</a>
+<a href="https://example.com/posts/hello/">Absolute</a>
+<a href="/posts/moved/">Moved</a>
+<a href="mailto:me@example.com">Mail</a>
+<img src="/images/logo.png" srcset="/images/logo.png 1x, /images/logo@2x.png 2x">
+<!-- <a href="/commented/">ignored</a> -->
+<script>var s = '<a href="/in-script/">';</script>`,
+		"about/index.html":       `<h2 id="team">Team</h2><a href="../posts/hello/#top">Hello</a><a href="#nowhere">Nowhere</a>`,
+		"posts/hello/index.html": `<a name="top"></a><a href="../../">Home</a>`,
+		"images/logo.png":        "png",
+	})
+
+	checker := NewChecker(outputPath, Options{BaseURL: "https://example.com/"})
+	report, err := checker.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if report.Pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", report.Pages)
+	}
+
+	expected := map[string][]string{
+		"/":       {"/about/#missing", "/posts/moved/", "/images/logo@2x.png"},
+		"/about/": {"#nowhere"},
+	}
+	if len(report.Broken) != len(expected) {
+		t.Errorf("Expected broken links in %d pages, got %v", len(expected), report.Broken)
+	}
+	for page, urls := range expected {
+		var got []string
+		for _, link := range report.Broken[page] {
+			got = append(got, link.URL)
+		}
+		if strings.Join(got, " ") != strings.Join(urls, " ") {
+			t.Errorf("Page %s: expected broken links %v, got %v", page, urls, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	if !strings.Contains(buf.String(), "/about/\n  #nowhere (anchor #nowhere not found)\n") {
+		t.Errorf("Unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestCheckBasePath(t *testing.T) {
+	outputPath := writeSite(t, map[string]string{
+		"index.html":       `<a href="/blog/about/">About</a><a href="/about/">Wrong</a>`,
+		"about/index.html": `About`,
+	})
+
+	report, err := NewChecker(outputPath, Options{BaseURL: "https://example.com/blog/"}).Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Count() != 1 || report.Broken["/"][0].URL != "/about/" {
+		t.Errorf("Expected only /about/ to be broken, got %v", report.Broken)
+	}
+}
+
+func TestCheckExternal(t *testing.T) {
+	// A local stand-in for the external site
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer standIn.Close()
+
+	outputPath := writeSite(t, map[string]string{
+		"index.html": `<a href="https://other.example.org/ok">OK</a><a href="https://other.example.org/gone">Gone</a>`,
+	})
+
+	// Skipped by default
+	report, err := NewChecker(outputPath, Options{BaseURL: "https://example.com/"}).Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Count() != 0 {
+		t.Errorf("Expected external links to be skipped, got %v", report.Broken)
+	}
+
+	report, err = NewChecker(outputPath, Options{
+		BaseURL:  "https://example.com/",
+		External: ExternalCheck,
+		StandIn:  standIn.URL,
+	}).Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Count() != 1 || report.Broken["/"][0].URL != "https://other.example.org/gone" {
+		t.Errorf("Expected only the gone link to be broken, got %v", report.Broken)
+	}
+}
+
+func TestCheckMissingOutput(t *testing.T) {
+	_, err := NewChecker(filepath.Join(t.TempDir(), "public"), Options{}).Check()
+	if err == nil {
+		t.Errorf("Expected an error for a missing output directory")
+	}
+}
@@ -0,0 +1,185 @@
+package menu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+// Entry is a link in a menu
+type Entry struct {
+	Identifier string
+	Name       string
+	// URL is the root-relative URL of the entry, or an absolute URL
+	URL    string
+	Weight int
+	Parent string
+	// Children are the entries nested under this one
+	Children Menu
+	// Active reports whether the entry links to the current page
+	Active bool
+	// Ancestor reports whether the current page is below the entry: one
+	// of its children is active, or the page's URL is under the entry's
+	Ancestor bool
+}
+
+// Menu is a list of entries sorted by weight, then name
+type Menu []*Entry
+
+// Menus holds the site's menus by name
+type Menus map[string]Menu
+
+// Build resolves the menus defined in the config and in the front matter
+// of pages. Config entries may link to a content file with pageRef, which
+// is resolved with index.
+func Build(cfg config.Config, pages []content.Page, index *content.Index) (Menus, error) {
+	flat := make(map[string][]*Entry)
+
+	names := make([]string, 0, len(cfg.Menus))
+	for name := range cfg.Menus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, item := range cfg.Menus[name] {
+			entry := &Entry{
+				Identifier: item.Identifier,
+				Name:       item.Name,
+				URL:        cfg.RelURL(item.URL),
+				Weight:     item.Weight,
+				Parent:     item.Parent,
+			}
+			if item.PageRef != "" {
+				if index == nil {
+					return nil, fmt.Errorf("menu %q: entry %q: no pages to resolve pageRef %q", name, item.Name, item.PageRef)
+				}
+				url, err := index.RelRef("", item.PageRef)
+				if err != nil {
+					return nil, fmt.Errorf("menu %q: entry %q: %w", name, item.Name, err)
+				}
+				entry.URL = url
+			}
+			flat[name] = append(flat[name], entry)
+		}
+	}
+
+	for _, page := range pages {
+		for name, item := range page.Menus {
+			entry := &Entry{
+				Identifier: item.Identifier,
+				Name:       item.Name,
+				URL:        page.RelPermalink,
+				Weight:     item.Weight,
+				Parent:     item.Parent,
+			}
+			if entry.Name == "" {
+				entry.Name = page.Title
+			}
+			flat[name] = append(flat[name], entry)
+		}
+	}
+
+	menus := make(Menus, len(flat))
+	for name, entries := range flat {
+		menu, err := nest(entries)
+		if err != nil {
+			return nil, fmt.Errorf("menu %q: %w", name, err)
+		}
+		menus[name] = menu
+	}
+	return menus, nil
+}
+
+// nest arranges entries into a tree by their Parent identifiers
+func nest(entries []*Entry) (Menu, error) {
+	byID := make(map[string]*Entry, len(entries))
+	for _, entry := range entries {
+		if entry.Identifier == "" {
+			entry.Identifier = entry.Name
+		}
+		if _, ok := byID[entry.Identifier]; ok {
+			return nil, fmt.Errorf("duplicate entry %q (set an identifier to tell them apart)", entry.Identifier)
+		}
+		byID[entry.Identifier] = entry
+	}
+
+	var root Menu
+	for _, entry := range entries {
+		if entry.Parent == "" {
+			root = append(root, entry)
+			continue
+		}
+		parent, ok := byID[entry.Parent]
+		if !ok {
+			return nil, fmt.Errorf("entry %q has unknown parent %q", entry.Identifier, entry.Parent)
+		}
+		parent.Children = append(parent.Children, entry)
+	}
+
+	// Entries in a parent cycle are never reached from the top level
+	if n := root.count(); n != len(entries) {
+		return nil, fmt.Errorf("%d entries are nested in a cycle", len(entries)-n)
+	}
+
+	root.sort()
+	return root, nil
+}
+
+// count returns the number of entries in the tree
+func (m Menu) count() int {
+	n := len(m)
+	for _, entry := range m {
+		n += entry.Children.count()
+	}
+	return n
+}
+
+// sort orders each level of the tree by weight, then name
+func (m Menu) sort() {
+	sort.SliceStable(m, func(i, j int) bool {
+		if m[i].Weight != m[j].Weight {
+			return m[i].Weight < m[j].Weight
+		}
+		return m[i].Name < m[j].Name
+	})
+	for _, entry := range m {
+		entry.Children.sort()
+	}
+}
+
+// ForPage returns a copy of the menus with the entries for the page at
+// url marked Active or Ancestor. home is the URL of the home page, which
+// isn't an ancestor of every page.
+func (m Menus) ForPage(url, home string) Menus {
+	marked := make(Menus, len(m))
+	for name, menu := range m {
+		marked[name], _ = menu.mark(url, home)
+	}
+	return marked
+}
+
+// mark copies the menu, marking entries for url, and reports whether an
+// entry in it is active
+func (m Menu) mark(url, home string) (Menu, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	marked := make(Menu, len(m))
+	found := false
+	for i, entry := range m {
+		e := *entry
+		var childActive bool
+		e.Children, childActive = entry.Children.mark(url, home)
+		e.Active = url != "" && e.URL == url
+		e.Ancestor = childActive ||
+			(url != "" && e.URL != home && strings.HasSuffix(e.URL, "/") && strings.HasPrefix(url, e.URL) && url != e.URL)
+		found = found || e.Active || childActive
+		marked[i] = &e
+	}
+	return marked, found
+}
@@ -0,0 +1,170 @@
+package menu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+func testSite() (config.Config, []content.Page, *content.Index) {
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://example.com/blog/"
+	cfg.Menus = map[string][]config.MenuEntry{
+		"main": {
+			{Name: "Home", URL: "/", Weight: 1},
+			{Name: "Docs", URL: "/docs/", Weight: 3},
+			{Name: "About", PageRef: "about.md", Weight: 2},
+			{Name: "GitHub", URL: "https://github.com/example", Weight: 10},
+		},
+	}
+
+	pages := []content.Page{
+		{
+			Path:         "/site/content/about.md",
+			Title:        "About Us",
+			RelPermalink: "/blog/about/",
+		},
+		{
+			Path:         "/site/content/docs/install.md",
+			Title:        "Install",
+			RelPermalink: "/blog/docs/install/",
+			Menus:        content.PageMenus{"main": {Parent: "Docs", Weight: 2}},
+		},
+		{
+			Path:         "/site/content/docs/usage.md",
+			Title:        "Usage",
+			RelPermalink: "/blog/docs/usage/",
+			Menus:        content.PageMenus{"main": {Parent: "Docs", Weight: 1}, "footer": {Name: "How to"}},
+		},
+	}
+
+	return cfg, pages, content.NewIndex("/site/content", pages)
+}
+
+func TestBuild(t *testing.T) {
+	cfg, pages, index := testSite()
+
+	menus, err := Build(cfg, pages, index)
+	if err != nil {
+		t.Fatalf("Failed to build menus: %v", err)
+	}
+
+	main := menus["main"]
+	var names []string
+	for _, entry := range main {
+		names = append(names, entry.Name)
+	}
+	if got, want := strings.Join(names, ","), "Home,About,Docs,GitHub"; got != want {
+		t.Fatalf("Expected main menu %s, got %s", want, got)
+	}
+
+	tests := []struct {
+		entry *Entry
+		url   string
+	}{
+		{main[0], "/blog/"},
+		{main[1], "/blog/about/"},
+		{main[2], "/blog/docs/"},
+		{main[3], "https://github.com/example"},
+	}
+	for _, tt := range tests {
+		if tt.entry.URL != tt.url {
+			t.Errorf("Expected %s to link to %s, got %s", tt.entry.Name, tt.url, tt.entry.URL)
+		}
+	}
+
+	// Children are sorted by weight and named after their page
+	docs := main[2].Children
+	if len(docs) != 2 || docs[0].Name != "Usage" || docs[1].Name != "Install" {
+		t.Fatalf("Expected Docs to contain Usage and Install, got %+v", docs)
+	}
+	if docs[0].URL != "/blog/docs/usage/" {
+		t.Errorf("Expected Usage to link to its page, got %s", docs[0].URL)
+	}
+
+	footer := menus["footer"]
+	if len(footer) != 1 || footer[0].Name != "How to" {
+		t.Errorf("Expected footer menu with How to, got %+v", footer)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []config.MenuEntry
+		want    string
+	}{
+		{
+			name:    "duplicate",
+			entries: []config.MenuEntry{{Name: "Home", URL: "/"}, {Name: "Home", URL: "/home/"}},
+			want:    "duplicate entry",
+		},
+		{
+			name:    "unknown parent",
+			entries: []config.MenuEntry{{Name: "Child", URL: "/child/", Parent: "Missing"}},
+			want:    "unknown parent",
+		},
+		{
+			name:    "cycle",
+			entries: []config.MenuEntry{{Name: "A", URL: "/a/", Parent: "B"}, {Name: "B", URL: "/b/", Parent: "A"}},
+			want:    "cycle",
+		},
+		{
+			name:    "unresolved pageRef",
+			entries: []config.MenuEntry{{Name: "Missing", PageRef: "missing.md"}},
+			want:    "unresolved reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Menus = map[string][]config.MenuEntry{"main": tt.entries}
+
+			_, err := Build(cfg, nil, content.NewIndex("/site/content", nil))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestForPage(t *testing.T) {
+	cfg, pages, index := testSite()
+
+	menus, err := Build(cfg, pages, index)
+	if err != nil {
+		t.Fatalf("Failed to build menus: %v", err)
+	}
+
+	marked := menus.ForPage("/blog/docs/usage/", "/blog/")
+	main := marked["main"]
+
+	if main[0].Active || main[0].Ancestor {
+		t.Errorf("Expected Home to be neither active nor an ancestor")
+	}
+	if main[2].Active || !main[2].Ancestor {
+		t.Errorf("Expected Docs to be an ancestor of the page")
+	}
+	if !main[2].Children[0].Active {
+		t.Errorf("Expected Usage to be active")
+	}
+	if main[2].Children[1].Active {
+		t.Errorf("Expected Install not to be active")
+	}
+
+	// The built menus are shared between pages and must stay unmarked
+	if menus["main"][2].Children[0].Active || menus["main"][2].Ancestor {
+		t.Errorf("Expected ForPage not to modify the original menus")
+	}
+
+	home := menus.ForPage("/blog/", "/blog/")["main"]
+	if !home[0].Active {
+		t.Errorf("Expected Home to be active on the home page")
+	}
+	if home[2].Ancestor {
+		t.Errorf("Expected Docs not to be an ancestor of the home page")
+	}
+}
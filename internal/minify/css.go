@@ -0,0 +1,152 @@
+package minify
+
+import (
+	"bytes"
+	"strings"
+)
+
+// CSS minifies a stylesheet by removing comments and redundant whitespace.
+// Strings and url() arguments are copied verbatim.
+func CSS(src []byte) []byte {
+	s := string(src)
+	out := make([]byte, 0, len(s))
+
+	// Track whether we're inside a declaration block, where whitespace
+	// before a colon is insignificant (unlike selectors such as "a :hover")
+	var blocks []bool
+	preludeStart := 0
+	inDeclarations := func() bool {
+		return len(blocks) > 0 && blocks[len(blocks)-1]
+	}
+
+	pendingSpace := false
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			// Comment: drop it, but keep it from gluing tokens together
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 4
+			}
+			pendingSpace = true
+
+		case isSpace(c):
+			pendingSpace = true
+			i++
+
+		case c == '"' || c == '\'':
+			out = appendCSSSpace(out, pendingSpace, c)
+			pendingSpace = false
+			end := scanQuoted(s, i)
+			out = append(out, s[i:end]...)
+			i = end
+
+		case hasPrefixFold(s[i:], "url(") && !isIdentChar(lastByte(out)):
+			out = appendCSSSpace(out, pendingSpace, c)
+			pendingSpace = false
+			end := strings.IndexByte(s[i:], ')')
+			if end < 0 {
+				out = append(out, s[i:]...)
+				i = len(s)
+			} else {
+				out = append(out, s[i:i+end+1]...)
+				i += end + 1
+			}
+
+		default:
+			if c == '}' {
+				// The last declaration in a block doesn't need its semicolon
+				out = bytes.TrimSuffix(out, []byte(";"))
+			}
+			if !(c == ':' && inDeclarations()) {
+				out = appendCSSSpace(out, pendingSpace, c)
+			}
+			pendingSpace = false
+
+			switch c {
+			case '{':
+				blocks = append(blocks, !isGroupingRule(out[preludeStart:]))
+				preludeStart = len(out) + 1
+			case '}':
+				if len(blocks) > 0 {
+					blocks = blocks[:len(blocks)-1]
+				}
+				preludeStart = len(out) + 1
+			case ';':
+				preludeStart = len(out) + 1
+			}
+
+			out = append(out, c)
+			i++
+		}
+	}
+
+	return bytes.TrimSpace(out)
+}
+
+// appendCSSSpace appends a single separating space unless the characters
+// on either side make it redundant
+func appendCSSSpace(out []byte, pending bool, next byte) []byte {
+	if !pending || len(out) == 0 {
+		return out
+	}
+	if strings.IndexByte("{};,>:", lastByte(out)) >= 0 {
+		return out
+	}
+	if strings.IndexByte("{};,>)!", next) >= 0 {
+		return out
+	}
+	return append(out, ' ')
+}
+
+// isGroupingRule reports whether a block prelude opens an at-rule whose
+// body contains rules rather than declarations
+func isGroupingRule(prelude []byte) bool {
+	p := strings.ToLower(strings.TrimSpace(string(prelude)))
+	for _, rule := range []string{"@media", "@supports", "@document", "@container", "@layer", "@scope"} {
+		if strings.HasPrefix(p, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanQuoted returns the index just past the quoted string starting at i
+func scanQuoted(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			// Unterminated string; stop at the line end like browsers do
+			return j
+		}
+	}
+	return len(s)
+}
+
+// lastByte returns the last byte of out, or 0 if it is empty
+func lastByte(out []byte) byte {
+	if len(out) == 0 {
+		return 0
+	}
+	return out[len(out)-1]
+}
+
+// hasPrefixFold reports whether s starts with prefix, ignoring ASCII case
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isIdentChar reports whether c can appear in a CSS or JS identifier
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,249 @@
+package minify
+
+import (
+	"strings"
+)
+
+// blockElements are elements around which whitespace never renders, so
+// surrounding whitespace-only text can be dropped entirely
+var blockElements = map[string]bool{
+	"html": true, "head": true, "body": true, "title": true, "meta": true,
+	"link": true, "style": true, "script": true, "noscript": true, "base": true,
+	"header": true, "footer": true, "main": true, "nav": true, "section": true,
+	"article": true, "aside": true, "div": true, "p": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "ul": true,
+	"ol": true, "li": true, "dl": true, "dt": true, "dd": true, "table": true,
+	"thead": true, "tbody": true, "tfoot": true, "tr": true, "td": true,
+	"th": true, "form": true, "fieldset": true, "figure": true,
+	"figcaption": true, "blockquote": true, "hr": true, "br": true,
+	"pre": true, "address": true, "details": true, "summary": true,
+	"template": true, "!doctype": true,
+}
+
+// rawTextElements have content that must not be treated as markup
+var rawTextElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// htmlToken is a lexical piece of an HTML document
+type htmlToken struct {
+	text  string
+	tag   string // lower-case tag name, empty for text
+	raw   bool   // verbatim content of a raw text element
+	isTag bool
+}
+
+// HTML minifies an HTML document. Comments are removed (except conditional
+// comments), whitespace is collapsed, inline <style> and <script> content is
+// minified, and <pre>/<textarea> content is left untouched.
+func HTML(src []byte) []byte {
+	tokens := tokenizeHTML(string(src))
+
+	var out strings.Builder
+	out.Grow(len(src))
+
+	for i, tok := range tokens {
+		if tok.isTag || tok.raw {
+			out.WriteString(tok.text)
+			continue
+		}
+
+		text := collapseSpace(tok.text)
+
+		// Drop whitespace next to block-level tags
+		if i == 0 || nextToBlock(tokens[i-1]) {
+			text = strings.TrimLeft(text, " ")
+		}
+		if i == len(tokens)-1 || nextToBlock(tokens[i+1]) {
+			text = strings.TrimRight(text, " ")
+		}
+
+		out.WriteString(text)
+	}
+
+	return []byte(out.String())
+}
+
+// nextToBlock reports whether whitespace adjacent to tok can be removed
+func nextToBlock(tok htmlToken) bool {
+	return tok.isTag && blockElements[tok.tag]
+}
+
+// tokenizeHTML splits a document into tags, text and raw element content
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+
+	for i := 0; i < len(s); {
+		if s[i] != '<' || i+1 >= len(s) || !startsTag(s[i+1]) {
+			// Text runs until the next tag
+			end := i + 1
+			for end < len(s) && !(s[end] == '<' && end+1 < len(s) && startsTag(s[end+1])) {
+				end++
+			}
+			tokens = append(tokens, htmlToken{text: s[i:end]})
+			i = end
+			continue
+		}
+
+		// Comments
+		if strings.HasPrefix(s[i:], "<!--") {
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				end = len(s)
+			} else {
+				end = i + 4 + end + 3
+			}
+			// Conditional comments carry meaning for old browsers
+			if strings.HasPrefix(s[i:], "<!--[if") {
+				tokens = append(tokens, htmlToken{text: s[i:end], isTag: true})
+			}
+			i = end
+			continue
+		}
+
+		end := scanTagEnd(s, i)
+		tagText := s[i:end]
+		name := tagName(tagText)
+		closing := strings.HasPrefix(tagText, "</")
+
+		tokens = append(tokens, htmlToken{text: minifyTag(tagText), tag: name, isTag: true})
+		i = end
+
+		// Copy the body of raw text elements verbatim (or minified)
+		if !closing && rawTextElements[name] && !strings.HasSuffix(tagText, "/>") {
+			closeIdx := indexFold(s[i:], "</"+name)
+			if closeIdx < 0 {
+				closeIdx = len(s) - i
+			}
+			body := s[i : i+closeIdx]
+			tokens = append(tokens, htmlToken{text: minifyRawText(name, tagText, body), raw: true})
+			i += closeIdx
+		}
+	}
+
+	return tokens
+}
+
+// minifyRawText minifies the content of <style> and <script> elements.
+// Scripts with a non-JavaScript type (e.g. JSON-LD or templates) are
+// only trimmed.
+func minifyRawText(name, openTag, body string) string {
+	switch name {
+	case "style":
+		return string(CSS([]byte(body)))
+	case "script":
+		typ := strings.ToLower(attrValue(openTag, "type"))
+		if typ == "" || typ == "module" || strings.Contains(typ, "javascript") || strings.Contains(typ, "ecmascript") {
+			return string(JS([]byte(body)))
+		}
+		return strings.TrimSpace(body)
+	default:
+		return body
+	}
+}
+
+// startsTag reports whether c can follow '<' at the start of markup
+func startsTag(c byte) bool {
+	return c == '/' || c == '!' || c == '?' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scanTagEnd returns the index just past the '>' closing the tag at i,
+// ignoring any '>' inside quoted attribute values
+func scanTagEnd(s string, i int) int {
+	var quote byte
+	for j := i + 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// tagName returns the lower-case element name of a tag
+func tagName(tag string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	end := 0
+	for end < len(name) && !isSpace(name[end]) && name[end] != '>' && name[end] != '/' {
+		end++
+	}
+	return strings.ToLower(name[:end])
+}
+
+// minifyTag collapses whitespace between attributes, leaving attribute
+// values untouched
+func minifyTag(tag string) string {
+	var out strings.Builder
+	out.Grow(len(tag))
+
+	var quote byte
+	pendingSpace := false
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if quote != 0 {
+			out.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if isSpace(c) {
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace {
+			// No space is needed before the end of the tag or around '='
+			prev := tag[0]
+			if out.Len() > 0 {
+				prev = out.String()[out.Len()-1]
+			}
+			if c != '>' && c != '=' && prev != '=' && !(c == '/' && i+1 < len(tag) && tag[i+1] == '>') {
+				out.WriteByte(' ')
+			}
+			pendingSpace = false
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+		}
+		out.WriteByte(c)
+	}
+
+	return out.String()
+}
+
+// attrValue returns the value of an attribute in an opening tag
+func attrValue(tag, name string) string {
+	lower := strings.ToLower(tag)
+	idx := strings.Index(lower, " "+name+"=")
+	if idx < 0 {
+		return ""
+	}
+	rest := tag[idx+len(name)+2:]
+	if rest == "" {
+		return ""
+	}
+	if rest[0] == '"' || rest[0] == '\'' {
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			return rest[1:]
+		}
+		return rest[1 : end+1]
+	}
+	end := strings.IndexAny(rest, " \t\n>")
+	if end < 0 {
+		return rest
+	}
+	return rest[:end]
+}
+
+// indexFold is strings.Index ignoring ASCII case
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
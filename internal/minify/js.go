@@ -0,0 +1,192 @@
+package minify
+
+import "strings"
+
+// regexPrecedingKeywords are keywords after which a slash starts a regular
+// expression literal rather than a division
+var regexPrecedingKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// JS minifies JavaScript by removing comments, indentation and blank lines.
+// Line breaks are preserved so automatic semicolon insertion keeps working,
+// and strings, template literals and regular expressions are copied verbatim.
+func JS(src []byte) []byte {
+	s := string(src)
+	out := make([]byte, 0, len(s))
+
+	// Whitespace is buffered and emitted once the next token is known:
+	// a newline if the run contained one, otherwise a single space
+	pendingSpace, pendingNewline := false, false
+	flush := func() {
+		if len(out) > 0 {
+			if pendingNewline {
+				if lastByte(out) != '\n' {
+					out = append(out, '\n')
+				}
+			} else if pendingSpace && lastByte(out) != '\n' {
+				out = append(out, ' ')
+			}
+		}
+		pendingSpace, pendingNewline = false, false
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\n' || c == '\r':
+			pendingNewline = true
+			i++
+
+		case isSpace(c):
+			pendingSpace = true
+			i++
+
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			// Line comment: skip to the end of the line
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end
+			}
+
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+				break
+			}
+			if strings.Contains(s[i:i+end+2], "\n") {
+				pendingNewline = true
+			} else {
+				pendingSpace = true
+			}
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			flush()
+			end := scanQuoted(s, i)
+			out = append(out, s[i:end]...)
+			i = end
+
+		case c == '`':
+			flush()
+			end := scanTemplateLiteral(s, i)
+			out = append(out, s[i:end]...)
+			i = end
+
+		case c == '/' && regexAllowed(out):
+			flush()
+			end := scanRegex(s, i)
+			out = append(out, s[i:end]...)
+			i = end
+
+		default:
+			flush()
+			out = append(out, c)
+			i++
+		}
+	}
+
+	return []byte(strings.TrimSpace(string(out)))
+}
+
+// regexAllowed reports whether a slash following the given output starts
+// a regular expression literal
+func regexAllowed(out []byte) bool {
+	// Look at the last non-whitespace character
+	end := len(out)
+	for end > 0 && isSpace(out[end-1]) {
+		end--
+	}
+	if end == 0 {
+		return true
+	}
+
+	last := out[end-1]
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", last) >= 0 {
+		return true
+	}
+
+	// A preceding keyword such as "return" also starts an expression
+	if isIdentChar(last) {
+		start := end
+		for start > 0 && isIdentChar(out[start-1]) {
+			start--
+		}
+		return regexPrecedingKeywords[string(out[start:end])]
+	}
+
+	return false
+}
+
+// scanRegex returns the index just past the regex literal starting at i,
+// including any flags
+func scanRegex(s string, i int) int {
+	inClass := false
+	j := i + 1
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			// Not a valid regex; treat the slash as a single character
+			return i + 1
+		case '/':
+			if !inClass {
+				j++
+				for j < len(s) && isIdentChar(s[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+	return len(s)
+}
+
+// scanTemplateLiteral returns the index just past the template literal
+// starting at i, skipping over nested ${...} expressions
+func scanTemplateLiteral(s string, i int) int {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			return j + 1
+		case s[j] == '$' && j+1 < len(s) && s[j+1] == '{':
+			j = scanTemplateExpression(s, j+2) - 1
+		}
+	}
+	return len(s)
+}
+
+// scanTemplateExpression returns the index just past the closing brace of
+// a template literal expression starting at i
+func scanTemplateExpression(s string, i int) int {
+	depth := 1
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '"', '\'':
+			j = scanQuoted(s, j) - 1
+		case '`':
+			j = scanTemplateLiteral(s, j) - 1
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(s)
+}
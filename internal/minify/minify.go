@@ -0,0 +1,63 @@
+// Package minify provides conservative, dependency-free minifiers for the
+// HTML, CSS, JavaScript and XML files produced by a build.
+//
+// The minifiers favour safety over size: they strip comments and redundant
+// whitespace but never rewrite identifiers or restructure code, so output
+// stays byte-for-byte equivalent where whitespace is significant (<pre>,
+// <textarea>, strings and template literals).
+package minify
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Minifier transforms the content of a single file
+type Minifier func([]byte) []byte
+
+// ForExtension returns the minifier for a file extension, or nil when the
+// file type isn't supported. Already minified files (*.min.js, *.min.css)
+// are left alone.
+func ForExtension(path string) Minifier {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css") {
+		return nil
+	}
+
+	switch filepath.Ext(name) {
+	case ".html", ".htm":
+		return HTML
+	case ".css":
+		return CSS
+	case ".js", ".mjs":
+		return JS
+	case ".xml", ".svg":
+		return XML
+	default:
+		return nil
+	}
+}
+
+// isSpace reports whether b is an ASCII whitespace character
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// collapseSpace replaces every run of whitespace with a single space
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	inSpace := false
+	for i := 0; i < len(s); i++ {
+		if isSpace(s[i]) {
+			if !inSpace {
+				sb.WriteByte(' ')
+				inSpace = true
+			}
+			continue
+		}
+		inSpace = false
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package minify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <!-- page metadata -->
+    <meta   charset="UTF-8">
+    <title>  My   Site </title>
+    <style>
+        body { color : red ; }
+    </style>
+</head>
+<body>
+    <p>Hello   <strong>big</strong>   <em>world</em></p>
+    <pre>
+  keep    this
+    exactly</pre>
+    <script>
+        // greet the user
+        var msg = "a  //  b";
+        console.log(msg)
+    </script>
+    <script type="application/ld+json">
+        {"@type": "WebSite"}
+    </script>
+</body>
+</html>`
+
+	expected := `<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><title>My Site</title><style>body{color:red}</style></head><body><p>Hello <strong>big</strong> <em>world</em></p><pre>
+  keep    this
+    exactly</pre><script>var msg = "a  //  b";
+console.log(msg)</script><script type="application/ld+json">{"@type": "WebSite"}</script></body></html>`
+
+	result := string(HTML([]byte(input)))
+	if result != expected {
+		t.Errorf("HTML() =\n%s\nwant\n%s", result, expected)
+	}
+}
+
+func TestHTMLKeepsConditionalComments(t *testing.T) {
+	input := `<head><!--[if IE]><p>old</p><![endif]--><!-- drop --></head>`
+	result := string(HTML([]byte(input)))
+	if !strings.Contains(result, "<!--[if IE]>") {
+		t.Errorf("Expected conditional comment to be kept, got %s", result)
+	}
+	if strings.Contains(result, "drop") {
+		t.Errorf("Expected regular comment to be removed, got %s", result)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Comments and whitespace",
+			input:    "/* header */\nh1 ,  h2 {\n  color : #333 ;\n  margin: 0 auto;\n}\n",
+			expected: "h1,h2{color:#333;margin:0 auto}",
+		},
+		{
+			name:     "Descendant pseudo selector keeps space",
+			input:    "div :first-child { top: 0 }",
+			expected: "div :first-child{top:0}",
+		},
+		{
+			name:     "Strings and urls are preserved",
+			input:    `a::after { content: "  x  "; background: url( "a b.png" ) }`,
+			expected: `a::after{content:"  x  ";background:url( "a b.png" )}`,
+		},
+		{
+			name:     "Calc keeps operator spacing",
+			input:    "p { width: calc(100% - 2rem) !important; }",
+			expected: "p{width:calc(100% - 2rem)!important}",
+		},
+		{
+			name:     "Media queries",
+			input:    "@media screen and (max-width: 600px) {\n  body { font-size: 14px; }\n}",
+			expected: "@media screen and (max-width:600px){body{font-size:14px}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := string(CSS([]byte(tt.input)))
+			if result != tt.expected {
+				t.Errorf("CSS() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJS(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Comments and indentation",
+			input:    "function f() {\n    // comment\n    return 1 /* inline */ + 2\n}\n\n\nf()",
+			expected: "function f() {\nreturn 1 + 2\n}\nf()",
+		},
+		{
+			name:     "Strings keep comment markers",
+			input:    `var a = "// not a comment", b = '/* nor this */'`,
+			expected: `var a = "// not a comment", b = '/* nor this */'`,
+		},
+		{
+			name:     "Regex literals",
+			input:    "var re = /ab+c\\/\\/d/g; // trailing\nx = a / b / c",
+			expected: "var re = /ab+c\\/\\/d/g;\nx = a / b / c",
+		},
+		{
+			name:     "Template literals",
+			input:    "const s = `line one\n    // kept ${ {a: 1}.a }\n`",
+			expected: "const s = `line one\n    // kept ${ {a: 1}.a }\n`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := string(JS([]byte(tt.input)))
+			if result != tt.expected {
+				t.Errorf("JS() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestXML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- generated -->
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/a b/</loc>
+    <note><![CDATA[  keep   me  ]]></note>
+  </url>
+</urlset>`
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://example.com/a b/</loc><note><![CDATA[  keep   me  ]]></note></url></urlset>`
+
+	result := string(XML([]byte(input)))
+	if result != expected {
+		t.Errorf("XML() =\n%s\nwant\n%s", result, expected)
+	}
+}
+
+func TestForExtension(t *testing.T) {
+	if ForExtension("css/style.css") == nil {
+		t.Error("Expected a minifier for .css files")
+	}
+	if ForExtension("js/app.min.js") != nil {
+		t.Error("Expected already minified files to be skipped")
+	}
+	if ForExtension("images/logo.png") != nil {
+		t.Error("Expected no minifier for .png files")
+	}
+}
@@ -0,0 +1,54 @@
+package minify
+
+import (
+	"strings"
+)
+
+// XML minifies an XML document (feeds, sitemaps, SVG) by removing comments
+// and whitespace-only text between elements. CDATA sections and text
+// content are left untouched.
+func XML(src []byte) []byte {
+	s := string(src)
+	var out strings.Builder
+	out.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += 4 + end + 3
+			}
+
+		case strings.HasPrefix(s[i:], "<![CDATA["):
+			end := strings.Index(s[i:], "]]>")
+			if end < 0 {
+				end = len(s) - i
+			} else {
+				end += 3
+			}
+			out.WriteString(s[i : i+end])
+			i += end
+
+		case s[i] == '<':
+			end := scanTagEnd(s, i)
+			out.WriteString(minifyTag(s[i:end]))
+			i = end
+
+		default:
+			end := strings.IndexByte(s[i:], '<')
+			if end < 0 {
+				end = len(s) - i
+			}
+			text := s[i : i+end]
+			if strings.TrimSpace(text) != "" {
+				out.WriteString(text)
+			}
+			i += end
+		}
+	}
+
+	return []byte(out.String())
+}
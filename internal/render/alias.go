@@ -0,0 +1,44 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// aliasTemplate is a minimal page redirecting an old URL to its page
+var aliasTemplate = template.Must(template.New("alias").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<title>{{.Permalink}}</title>
+<link rel="canonical" href="{{.Permalink}}">
+<meta name="robots" content="noindex">
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="0; url={{.URL}}">
+</head>
+<body>
+<p>This page has moved to <a href="{{.URL}}">{{.Permalink}}</a>.</p>
+</body>
+</html>
+`))
+
+// RenderAlias writes a page at outputPath that redirects to url, with
+// permalink as the canonical URL
+func (r *Renderer) RenderAlias(url, permalink, outputPath string) error {
+	data := map[string]interface{}{
+		"URL":       url,
+		"Permalink": permalink,
+		"Lang":      r.config.Language,
+	}
+
+	var buf bytes.Buffer
+	if err := aliasTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+}
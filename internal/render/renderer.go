@@ -1,28 +1,147 @@
 package render
 
 import (
+	"bytes"
+	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/dikaio/scribe/internal/assets"
 	"github.com/dikaio/scribe/internal/config"
 	"github.com/dikaio/scribe/internal/content"
+	"github.com/dikaio/scribe/internal/i18n"
+	"github.com/dikaio/scribe/internal/menu"
+	"github.com/dikaio/scribe/internal/minify"
 )
 
 // Renderer handles rendering pages to HTML files
 type Renderer struct {
 	templateManager *TemplateManager
+	assets          *assets.Pipeline
 	config          config.Config
 	devMode         bool
+	minify          bool
+	index           *content.Index
+	menus           menu.Menus
+	data            map[string]interface{}
+	pages           content.Pages
+	translations    *i18n.Bundle
+	languages       []Language
 }
 
 // NewRenderer creates a new renderer
 func NewRenderer(cfg config.Config) *Renderer {
-	return &Renderer{
-		templateManager: NewTemplateManager(cfg),
+	templateManager := NewTemplateManager(cfg)
+
+	// Expose the asset pipeline to templates
+	pipeline := assets.NewPipeline(cfg)
+	templateManager.AddFuncs(pipeline.FuncMap())
+
+	r := &Renderer{
+		templateManager: templateManager,
+		assets:          pipeline,
 		config:          cfg,
 		devMode:         false,
+		minify:          cfg.Minify,
+	}
+
+	// Cross-references to other content files
+	templateManager.AddFuncs(template.FuncMap{
+		"ref": func(from interface{}, ref string) (string, error) {
+			return r.resolveRef(from, ref, true)
+		},
+		"relref": func(from interface{}, ref string) (string, error) {
+			return r.resolveRef(from, ref, false)
+		},
+	})
+
+	// Strings from the i18n string tables, in the site's language
+	templateManager.AddFuncs(template.FuncMap{
+		"i18n": func(id string, args ...interface{}) (string, error) {
+			if r.translations == nil {
+				return id, nil
+			}
+			var arg interface{}
+			if len(args) > 0 {
+				arg = args[0]
+			}
+			return r.translations.Translate(r.config.Language, id, arg)
+		},
+	})
+
+	return r
+}
+
+// SetIndex sets the page index used by the ref and relref functions
+func (r *Renderer) SetIndex(index *content.Index) {
+	r.index = index
+}
+
+// SetMenus sets the menus exposed to templates as .Site.Menus
+func (r *Renderer) SetMenus(menus menu.Menus) {
+	r.menus = menus
+}
+
+// SetPages sets the pages exposed to templates as .Site.RegularPages,
+// newest first
+func (r *Renderer) SetPages(pages []content.Page) {
+	regular := make(content.Pages, len(pages))
+	copy(regular, pages)
+	sort.Slice(regular, func(i, j int) bool {
+		if !regular[i].Date.Equal(regular[j].Date) {
+			return regular[i].Date.After(regular[j].Date)
+		}
+		return regular[i].Path < regular[j].Path
+	})
+	r.pages = regular
+}
+
+// SetData sets the data files exposed to templates as .Site.Data
+func (r *Renderer) SetData(data map[string]interface{}) {
+	r.data = data
+}
+
+// SetLanguages sets the languages exposed to templates as .Site.Languages
+func (r *Renderer) SetLanguages(languages []Language) {
+	r.languages = languages
+}
+
+// SetTranslations sets the string tables used by the i18n function
+func (r *Renderer) SetTranslations(translations *i18n.Bundle) {
+	r.translations = translations
+}
+
+// resolveRef resolves a reference for the ref and relref functions.
+// References are relative to the page in from (a page or the template
+// data), or to the content directory.
+func (r *Renderer) resolveRef(from interface{}, ref string, absolute bool) (string, error) {
+	if r.index == nil {
+		return "", fmt.Errorf("cannot resolve %q: content not loaded", ref)
 	}
+
+	var source string
+	switch v := from.(type) {
+	case content.Page:
+		source = v.Path
+	case *content.Page:
+		source = v.Path
+	case map[string]interface{}:
+		if page, ok := v["Page"].(content.Page); ok {
+			source = page.Path
+		}
+	}
+
+	if absolute {
+		return r.index.Ref(source, ref)
+	}
+	return r.index.RelRef(source, ref)
+}
+
+// SetMinify enables or disables minification of rendered HTML
+func (r *Renderer) SetMinify(enabled bool) {
+	r.minify = enabled
 }
 
 // SetDevMode enables or disables development mode (disables caching)
@@ -35,26 +154,39 @@ func (r *Renderer) SetDevMode(enabled bool) {
 	}
 }
 
+// Assets returns the asset pipeline shared with templates
+func (r *Renderer) Assets() *assets.Pipeline {
+	return r.assets
+}
+
 // Init initializes the renderer
 func (r *Renderer) Init(sitePath string) error {
+	r.assets.Reset(sitePath)
 	return r.templateManager.LoadTemplates(sitePath)
 }
 
-// createOutputFile creates output file and ensures directory exists
-func (r *Renderer) createOutputFile(outputPath string) (*os.File, error) {
+// executeToFile executes a template and writes the result to outputPath,
+// creating the output directory and minifying the HTML when enabled
+func (r *Renderer) executeToFile(tmpl *template.Template, data interface{}, outputPath string) error {
+	// Render into a buffer first so a failing template never leaves
+	// a half-written file behind
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	output := buf.Bytes()
+	if r.minify {
+		output = minify.HTML(output)
+	}
+
 	// Create output directory if it doesn't exist
 	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return nil, err
+		return err
 	}
 
-	// Create output file
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return nil, err
-	}
-	
-	return f, nil
+	return os.WriteFile(outputPath, output, 0644)
 }
 
 // RenderPage renders a page to an HTML file
@@ -79,48 +211,38 @@ func (r *Renderer) RenderPage(page content.Page, outputPath string) error {
 		}
 	}
 
-	// Create output file
-	f, err := r.createOutputFile(outputPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
 	// Prepare template data
 	data := map[string]interface{}{
-		"Site":    r.config,
-		"Page":    page,
-		"Content": template.HTML(page.HTML),
+		"Site":      r.site(page.RelPermalink),
+		"Page":      page,
+		"Content":   template.HTML(page.HTML),
+		"Resources": r.assets.PageResources(page.Path, page.URL),
 	}
 
 	// Execute template
-	return tmpl.Execute(f, data)
+	return r.executeToFile(tmpl, data, outputPath)
 }
 
-// RenderList renders a list page (e.g., index, tag list)
-func (r *Renderer) RenderList(title string, pages []content.Page, outputPath string) error {
+// RenderList renders a list page (e.g., index, tag list) published at the
+// site path url
+func (r *Renderer) RenderList(title, url string, pages []content.Page, outputPath string) error {
 	// Get template
 	tmpl, err := r.templateManager.GetTemplate("list")
 	if err != nil {
 		return err
 	}
 
-	// Create output file
-	f, err := r.createOutputFile(outputPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
 	// Prepare template data
 	data := map[string]interface{}{
-		"Site":  r.config,
-		"Title": title,
-		"Pages": pages,
+		"Site":         r.site(r.config.RelURL(url)),
+		"Title":        title,
+		"Pages":        pages,
+		"Permalink":    r.config.AbsURL(url),
+		"RelPermalink": r.config.RelURL(url),
 	}
 
 	// Execute template
-	return tmpl.Execute(f, data)
+	return r.executeToFile(tmpl, data, outputPath)
 }
 
 // RenderHome renders the home page
@@ -135,20 +257,31 @@ func (r *Renderer) RenderHome(pages []content.Page, outputPath string) error {
 		}
 	}
 
-	// Create output file
-	f, err := r.createOutputFile(outputPath)
+	// Prepare template data
+	data := map[string]interface{}{
+		"Site":         r.site(r.config.RelURL("/")),
+		"Title":        r.config.Title,
+		"Pages":        pages,
+		"Permalink":    r.config.AbsURL("/"),
+		"RelPermalink": r.config.RelURL("/"),
+	}
+
+	// Execute template
+	return r.executeToFile(tmpl, data, outputPath)
+}
+
+// Render404 renders the page served for missing URLs, using the 404 layout
+func (r *Renderer) Render404(pages []content.Page, outputPath string) error {
+	tmpl, err := r.templateManager.GetTemplate("404")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	// Prepare template data
 	data := map[string]interface{}{
-		"Site":  r.config,
-		"Title": r.config.Title,
+		"Site":  r.site(""),
+		"Title": "Page Not Found",
 		"Pages": pages,
 	}
 
-	// Execute template
-	return tmpl.Execute(f, data)
+	return r.executeToFile(tmpl, data, outputPath)
 }
@@ -288,7 +288,7 @@ func TestRenderList(t *testing.T) {
 	outputPath := filepath.Join(tempDir, "public", "tags", "test", "index.html")
 
 	// Render the list
-	err := renderer.RenderList("Test Tag", pages, outputPath)
+	err := renderer.RenderList("Test Tag", "tags/test/", pages, outputPath)
 	if err != nil {
 		t.Fatalf("Failed to render list: %v", err)
 	}
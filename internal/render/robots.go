@@ -0,0 +1,50 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// defaultRobotsTemplate keeps crawlers away from everything but production
+// builds
+const defaultRobotsTemplate = `User-agent: *
+{{if .Site.IsProduction}}Disallow:{{else}}Disallow: /{{end}}
+
+Sitemap: {{.Sitemap}}
+`
+
+// RenderRobots writes robots.txt to outputPath from the robots.txt layout
+// of the site or theme, or from a built-in default. sitemapURL is the
+// sitemap, or the sitemap index when it was split.
+func (r *Renderer) RenderRobots(sitePath, sitemapURL, outputPath string) error {
+	text := defaultRobotsTemplate
+	for _, dir := range []string{
+		filepath.Join(sitePath, r.config.LayoutDir),
+		filepath.Join(sitePath, "themes", r.config.Theme, "layouts"),
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, "robots.txt")); err == nil {
+			text = string(data)
+			break
+		}
+	}
+
+	// robots.txt is plain text, so it must not be HTML escaped
+	tmpl, err := template.New("robots.txt").Funcs(template.FuncMap(r.templateManager.funcMap)).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing robots.txt template: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"Site":    r.site(""),
+		"Sitemap": sitemapURL,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0644)
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+	"github.com/dikaio/scribe/internal/menu"
+)
+
+// Site is .Site in templates: the configuration, plus what is known about
+// the site once its content is loaded
+type Site struct {
+	config.Config
+	// Menus are the site menus, with the entries for the page being
+	// rendered marked active
+	Menus menu.Menus
+	// RegularPages are the site's pages, newest first. Related finds the
+	// pages related to one: .Site.RegularPages.Related .Page
+	RegularPages content.Pages
+	// Data holds the files in data/, e.g. .Site.Data.team.members for
+	// data/team/members.yml
+	Data map[string]interface{}
+	// Languages are the languages of a multilingual site, for language
+	// switchers
+	Languages []Language
+}
+
+// Language is a language of a multilingual site in .Site.Languages
+type Language struct {
+	config.Language
+	// URL is the URL of the language's home page
+	URL string
+	// Current reports whether the page being rendered is in the language
+	Current bool
+}
+
+// site returns .Site for rendering the page at the root-relative url, or
+// for a page without a URL when url is empty
+func (r *Renderer) site(url string) Site {
+	return Site{
+		Config:       r.config,
+		Menus:        r.menus.ForPage(url, r.config.RelURL("/")),
+		Data:         r.data,
+		RegularPages: r.pages,
+		Languages:    r.languagesFor(r.config.Language),
+	}
+}
+
+// languagesFor returns the site's languages with lang marked current
+func (r *Renderer) languagesFor(lang string) []Language {
+	languages := make([]Language, len(r.languages))
+	for i, language := range r.languages {
+		language.Current = language.Code == lang
+		languages[i] = language
+	}
+	return languages
+}
@@ -5,13 +5,21 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/templates"
 )
 
+// fallbackLayouts are embedded layouts used when neither the site nor the
+// theme provides one. They define "content" for the site's base template.
+var fallbackLayouts = map[string]string{
+	"404": templates.NotFoundTemplate,
+}
+
 // TemplateCache represents a cached template
 type TemplateCache struct {
 	Template *template.Template
@@ -43,6 +51,12 @@ func NewTemplateManager(cfg config.Config) *TemplateManager {
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		// Static files are served from the site root, while the pages of
+		// a language may be published below it
+		"relURL": cfg.Root().RelURL,
+		"absURL": cfg.Root().AbsURL,
+		"relLangURL": cfg.RelURL,
+		"absLangURL": cfg.AbsURL,
 	}
 
 	return &TemplateManager{
@@ -54,6 +68,14 @@ func NewTemplateManager(cfg config.Config) *TemplateManager {
 	}
 }
 
+// AddFuncs registers additional template functions. It must be called
+// before LoadTemplates, since functions are bound when templates are parsed.
+func (tm *TemplateManager) AddFuncs(funcs template.FuncMap) {
+	for name, fn := range funcs {
+		tm.funcMap[name] = fn
+	}
+}
+
 // DisableCaching disables template caching (for development mode)
 func (tm *TemplateManager) DisableCaching() {
 	tm.cachingEnabled = false
@@ -175,9 +197,27 @@ func (tm *TemplateManager) LoadTemplates(sitePath string) error {
 	
 	// Collect site templates (overrides)
 	collectTemplates(siteLayoutPath, baseTemplatePath, true)
+
+	// Partials are shared by all layouts; site partials override theme
+	// partials of the same name
+	partials := make(map[string]string)
+	for _, dir := range []string{filepath.Join(themePath, "partials"), filepath.Join(siteLayoutPath, "partials")} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.html"))
+		for _, file := range files {
+			partials[filepath.Base(file)] = file
+		}
+	}
+	partialFiles := make([]string, 0, len(partials))
+	for _, file := range partials {
+		partialFiles = append(partialFiles, file)
+	}
+	sort.Strings(partialFiles)
 	
 	// Parse all template combinations, using cache where possible
-	for name, files := range layoutTemplates {
+	for name, layoutFiles := range layoutTemplates {
+		// Partials count as sources of every layout for caching
+		files := append(append([]string{}, layoutFiles...), partialFiles...)
+
 		// Check if the template needs to be reloaded
 		needsUpdate, modTime, err := tm.templateNeedsUpdate(name, files)
 		if err != nil {
@@ -193,10 +233,13 @@ func (tm *TemplateManager) LoadTemplates(sitePath string) error {
 		}
 
 		// Parse the template set
-		tmpl, err := template.New(filepath.Base(files[0])).Funcs(tm.funcMap).ParseFiles(files...)
+		tmpl, err := template.New(filepath.Base(layoutFiles[0])).Funcs(tm.funcMap).ParseFiles(layoutFiles...)
 		if err != nil {
 			return fmt.Errorf("error parsing template %s: %v", name, err)
 		}
+		if err := addPartials(tmpl, partialFiles); err != nil {
+			return err
+		}
 		
 		// Update the template in the current instance
 		tm.templates[name] = tmpl
@@ -222,6 +265,51 @@ func (tm *TemplateManager) LoadTemplates(sitePath string) error {
 		}
 	}
 
+	// Fill in missing layouts from the embedded defaults
+	for name, text := range fallbackLayouts {
+		if _, exists := layoutTemplates[name]; exists {
+			continue
+		}
+
+		tmpl, err := template.New(filepath.Base(baseTemplatePath)).Funcs(tm.funcMap).ParseFiles(baseTemplatePath)
+		if err != nil {
+			return fmt.Errorf("error parsing template %s: %v", name, err)
+		}
+		if _, err := tmpl.New(name + ".html").Parse(text); err != nil {
+			return fmt.Errorf("error parsing embedded template %s: %v", name, err)
+		}
+		if err := addPartials(tmpl, partialFiles); err != nil {
+			return err
+		}
+		tm.templates[name] = tmpl
+	}
+
+	return nil
+}
+
+// addPartials parses the built-in templates and partial files into a
+// template set, where layouts include them with
+// {{template "_internal/opengraph.html" .}} or {{template "partials/name.html" .}}
+func addPartials(tmpl *template.Template, files []string) error {
+	internal, err := templates.GetInternalTemplates()
+	if err != nil {
+		return err
+	}
+	for name, text := range internal {
+		if _, err := tmpl.New(name).Parse(text); err != nil {
+			return fmt.Errorf("error parsing embedded template %s: %v", name, err)
+		}
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err := tmpl.New("partials/" + filepath.Base(file)).Parse(string(data)); err != nil {
+			return fmt.Errorf("error parsing partial %s: %v", filepath.Base(file), err)
+		}
+	}
 	return nil
 }
 
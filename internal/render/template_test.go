@@ -150,9 +150,9 @@ func TestLoadTemplates(t *testing.T) {
 		t.Fatalf("Failed to load templates: %v", err)
 	}
 
-	// Check that templates were loaded
-	if len(tm.templates) != 3 {
-		t.Errorf("Expected 3 templates, got %d", len(tm.templates))
+	// Check that templates were loaded, plus the embedded 404 fallback
+	if len(tm.templates) != 4 {
+		t.Errorf("Expected 4 templates, got %d", len(tm.templates))
 	}
 
 	// Check if base template exists
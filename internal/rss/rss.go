@@ -0,0 +1,120 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+// maxItems is the most posts a feed lists, newest first
+const maxItems = 20
+
+// RSS represents the root element of a feed
+type RSS struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel Channel  `xml:"channel"`
+}
+
+// Channel describes the site and holds the feed's items
+type Channel struct {
+	Title         string `xml:"title"`
+	Link          string `xml:"link"`
+	Description   string `xml:"description"`
+	Language      string `xml:"language,omitempty"`
+	LastBuildDate string `xml:"lastBuildDate,omitempty"`
+	Items         []Item `xml:"item"`
+}
+
+// Item is a post in the feed
+type Item struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description,omitempty"`
+}
+
+// Generator handles feed generation
+type Generator struct {
+	config config.Config
+	minify bool
+}
+
+// NewGenerator creates a feed generator for a site, or for one language
+// of a multilingual site
+func NewGenerator(cfg config.Config) *Generator {
+	return &Generator{
+		config: cfg,
+		minify: cfg.Minify,
+	}
+}
+
+// SetMinify controls whether the feed is written without indentation
+func (g *Generator) SetMinify(enabled bool) {
+	g.minify = enabled
+}
+
+// Generate writes the feed of the newest posts among pages to outputPath.
+// Drafts and pages that aren't posts are left out.
+func (g *Generator) Generate(pages []content.Page, outputPath string) error {
+	posts := make([]content.Page, 0, len(pages))
+	for _, page := range pages {
+		if page.IsPost && !page.Draft {
+			posts = append(posts, page)
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Date.After(posts[j].Date)
+	})
+	if len(posts) > maxItems {
+		posts = posts[:maxItems]
+	}
+
+	channel := Channel{
+		Title:       g.config.Title,
+		Link:        g.config.AbsURL("/"),
+		Description: g.config.Description,
+		Language:    g.config.Language,
+	}
+	if len(posts) > 0 && !posts[0].Date.IsZero() {
+		channel.LastBuildDate = posts[0].Date.Format(time.RFC1123Z)
+	}
+	for _, post := range posts {
+		item := Item{
+			Title:       post.Title,
+			Link:        post.Permalink,
+			GUID:        post.Permalink,
+			Description: post.Description,
+		}
+		if !post.Date.IsZero() {
+			item.PubDate = post.Date.Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for feed: %w", err)
+	}
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create feed file: %w", err)
+	}
+	defer f.Close()
+
+	f.WriteString(xml.Header)
+	encoder := xml.NewEncoder(f)
+	if !g.minify {
+		encoder.Indent("", "  ")
+	}
+	if err := encoder.Encode(RSS{Version: "2.0", Channel: channel}); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return nil
+}
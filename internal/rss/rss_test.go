@@ -0,0 +1,79 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := config.Config{
+		Title:       "Mein Blog",
+		Description: "Notizen",
+		BaseURL:     "https://example.com/de/",
+		Language:    "de",
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pages := []content.Page{
+		{Title: "Über", Permalink: "https://example.com/de/ueber/", Date: now},
+		{Title: "Entwurf", Permalink: "https://example.com/de/posts/entwurf/", Date: now, IsPost: true, Draft: true},
+		{Title: "Alt", Permalink: "https://example.com/de/posts/alt/", Date: now.Add(-48 * time.Hour), IsPost: true},
+		{Title: "Neu", Description: "Der neueste", Permalink: "https://example.com/de/posts/neu/", Date: now, IsPost: true},
+	}
+	for i := 0; i < maxItems; i++ {
+		pages = append(pages, content.Page{
+			Title:     fmt.Sprintf("Archiv %d", i),
+			Permalink: fmt.Sprintf("https://example.com/de/posts/archiv-%d/", i),
+			Date:      now.Add(-time.Duration(72+i) * time.Hour),
+			IsPost:    true,
+		})
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "index.xml")
+	if err := NewGenerator(cfg).Generate(pages, outputPath); err != nil {
+		t.Fatalf("Failed to generate feed: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read feed: %v", err)
+	}
+	var feed RSS
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+
+	channel := feed.Channel
+	if channel.Title != "Mein Blog" || channel.Link != "https://example.com/de/" || channel.Language != "de" {
+		t.Errorf("Expected the channel of the German site, got %+v", channel)
+	}
+	if len(channel.Items) != maxItems {
+		t.Fatalf("Expected %d items, got %d", maxItems, len(channel.Items))
+	}
+
+	first := channel.Items[0]
+	if first.Title != "Neu" || first.Description != "Der neueste" {
+		t.Errorf("Expected the newest post first, got %+v", first)
+	}
+	if first.PubDate != "Wed, 01 May 2024 12:00:00 +0000" {
+		t.Errorf("Expected RFC 1123 pubDate, got %q", first.PubDate)
+	}
+	if channel.LastBuildDate != first.PubDate {
+		t.Errorf("Expected lastBuildDate %q, got %q", first.PubDate, channel.LastBuildDate)
+	}
+	if channel.Items[1].Title != "Alt" {
+		t.Errorf("Expected 'Alt' second, got %q", channel.Items[1].Title)
+	}
+	for _, item := range channel.Items {
+		if item.Title == "Über" || item.Title == "Entwurf" {
+			t.Errorf("Expected only published posts, got %q", item.Title)
+		}
+	}
+}
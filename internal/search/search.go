@@ -0,0 +1,175 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+// Fields lists the fields an index entry can hold
+var Fields = []string{"title", "url", "summary", "tags", "section", "date", "content"}
+
+// DefaultFields are indexed when the configuration doesn't list any
+var DefaultFields = []string{"title", "url", "summary", "tags", "section", "content"}
+
+// rootShard is the shard holding pages outside any section
+const rootShard = "_root"
+
+var (
+	// skipElementRe matches elements whose text isn't page content
+	skipElementRe = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)>`)
+	// tagRe matches HTML tags
+	tagRe = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Manifest lists the shards of an index split by section
+type Manifest struct {
+	Shards map[string]string `json:"shards"`
+}
+
+// Generator writes the search index
+type Generator struct {
+	config config.Config
+	fields []string
+}
+
+// NewGenerator creates a search index generator for the configured fields
+func NewGenerator(cfg config.Config) (*Generator, error) {
+	fields := cfg.SearchIndex.Fields
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+
+	for _, field := range fields {
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown search index field %q (use %s)", field, strings.Join(Fields, ", "))
+		}
+	}
+
+	return &Generator{
+		config: cfg,
+		fields: fields,
+	}, nil
+}
+
+// isField reports whether name is a supported field
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate writes search.json to outputPath. When sharding by section,
+// each section gets its own file under search/ and search.json lists them.
+func (g *Generator) Generate(pages []content.Page, outputPath string) error {
+	// Keep the output stable between builds
+	sorted := make([]content.Page, len(pages))
+	copy(sorted, pages)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].RelPermalink < sorted[j].RelPermalink
+	})
+
+	if !g.config.SearchIndex.ShardBySection {
+		entries := make([]map[string]interface{}, 0, len(sorted))
+		for _, page := range sorted {
+			entries = append(entries, g.entry(page))
+		}
+		return writeJSON(filepath.Join(outputPath, "search.json"), entries)
+	}
+
+	shards := make(map[string][]map[string]interface{})
+	for _, page := range sorted {
+		shard := page.Section
+		if shard == "" {
+			shard = rootShard
+		}
+		shards[shard] = append(shards[shard], g.entry(page))
+	}
+
+	manifest := Manifest{Shards: make(map[string]string, len(shards))}
+	for shard, entries := range shards {
+		file := "search/" + shard + ".json"
+		if err := writeJSON(filepath.Join(outputPath, filepath.FromSlash(file)), entries); err != nil {
+			return err
+		}
+		manifest.Shards[shard] = g.config.RelURL(file)
+	}
+
+	return writeJSON(filepath.Join(outputPath, "search.json"), manifest)
+}
+
+// entry returns the index entry of a page with the configured fields
+func (g *Generator) entry(page content.Page) map[string]interface{} {
+	entry := make(map[string]interface{}, len(g.fields))
+	for _, field := range g.fields {
+		switch field {
+		case "title":
+			entry["title"] = page.Title
+		case "url":
+			entry["url"] = page.RelPermalink
+		case "summary":
+			entry["summary"] = g.summary(page)
+		case "tags":
+			tags := page.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			entry["tags"] = tags
+		case "section":
+			entry["section"] = page.Section
+		case "date":
+			if !page.Date.IsZero() {
+				entry["date"] = page.Date.Format("2006-01-02")
+			}
+		case "content":
+			entry["content"] = PlainText(page.HTML)
+		}
+	}
+	return entry
+}
+
+// summary returns the page description, or the first SummaryLength words
+// of its text
+func (g *Generator) summary(page content.Page) string {
+	if page.Description != "" {
+		return page.Description
+	}
+
+	words := strings.Fields(PlainText(page.HTML))
+	if g.config.SummaryLength > 0 && len(words) > g.config.SummaryLength {
+		return strings.Join(words[:g.config.SummaryLength], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
+
+// PlainText returns the text of an HTML fragment with tags removed,
+// entities decoded and whitespace collapsed
+func PlainText(s string) string {
+	s = skipElementRe.ReplaceAllString(s, " ")
+	s = tagRe.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// writeJSON writes v as JSON to path, creating its directory
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for search index: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,146 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dikaio/scribe/internal/config"
+	"github.com/dikaio/scribe/internal/content"
+)
+
+func testPages() []content.Page {
+	return []content.Page{
+		{
+			Title:        "Hello",
+			RelPermalink: "/posts/hello/",
+			Date:         time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Tags:         []string{"intro"},
+			Section:      "posts",
+			HTML:         "<h1>Hello</h1>\n<p>Fish &amp; chips, <em>twice</em>.</p><script>var x = 1;</script>",
+		},
+		{
+			Title:        "About",
+			Description:  "About this site",
+			RelPermalink: "/about/",
+			HTML:         "<p>About.</p>",
+		},
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText("<h1>Title</h1>\n<p>Fish &amp; chips,\n<em>twice</em>.</p><style>p { color: red }</style>")
+	if want := "Title Fish & chips, twice ."; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	outputPath := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.SummaryLength = 2
+
+	generator, err := NewGenerator(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.Generate(testPages(), outputPath); err != nil {
+		t.Fatalf("Failed to generate search index: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputPath, "search.json"))
+	if err != nil {
+		t.Fatalf("Expected search.json to be written: %v", err)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Failed to parse search.json: %v", err)
+	}
+
+	// Entries are sorted by URL
+	want := []map[string]interface{}{
+		{
+			"title":   "About",
+			"url":     "/about/",
+			"summary": "About this site",
+			"tags":    []interface{}{},
+			"section": "",
+			"content": "About.",
+		},
+		{
+			"title":   "Hello",
+			"url":     "/posts/hello/",
+			"summary": "Hello Fish…",
+			"tags":    []interface{}{"intro"},
+			"section": "posts",
+			"content": "Hello Fish & chips, twice .",
+		},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Expected entries %v, got %v", want, entries)
+	}
+}
+
+func TestGenerateFields(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SearchIndex.Fields = []string{"title", "date"}
+
+	generator, err := NewGenerator(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	entry := generator.entry(testPages()[0])
+	if want := map[string]interface{}{"title": "Hello", "date": "2024-02-01"}; !reflect.DeepEqual(entry, want) {
+		t.Errorf("Expected entry %v, got %v", want, entry)
+	}
+
+	cfg.SearchIndex.Fields = []string{"title", "body"}
+	if _, err := NewGenerator(cfg); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestGenerateShards(t *testing.T) {
+	outputPath := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://example.com/docs/"
+	cfg.SearchIndex.Fields = []string{"url"}
+	cfg.SearchIndex.ShardBySection = true
+
+	generator, err := NewGenerator(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+	if err := generator.Generate(testPages(), outputPath); err != nil {
+		t.Fatalf("Failed to generate search index: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputPath, "search.json"))
+	if err != nil {
+		t.Fatalf("Expected search.json to be written: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to parse search.json: %v", err)
+	}
+	wantShards := map[string]string{
+		"_root": "/docs/search/_root.json",
+		"posts": "/docs/search/posts.json",
+	}
+	if !reflect.DeepEqual(manifest.Shards, wantShards) {
+		t.Errorf("Expected shards %v, got %v", wantShards, manifest.Shards)
+	}
+
+	data, err = os.ReadFile(filepath.Join(outputPath, "search", "posts.json"))
+	if err != nil {
+		t.Fatalf("Expected the posts shard to be written: %v", err)
+	}
+	if want := `[{"url":"/posts/hello/"}]`; string(data) != want {
+		t.Errorf("Expected posts shard %s, got %s", want, data)
+	}
+}
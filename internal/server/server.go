@@ -5,7 +5,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dikaio/scribe/internal/build"
@@ -14,37 +17,123 @@ import (
 
 // Server represents the development server
 type Server struct {
-	config  config.Config
-	builder *build.Builder
-	watcher *build.Watcher
-	port    int
-	quiet   bool
+	config     config.Config
+	loadConfig func(sitePath string) (config.Config, error)
+	builder    *build.Builder
+	watcher    *build.Watcher
+	port       int
+	quiet      bool
+
+	// mu guards mounted, the handler serving the output directory at the
+	// base path, which is replaced when the config moves either
+	mu      sync.RWMutex
+	mounted http.Handler
 }
 
 // NewServer creates a new development server
 func NewServer(cfg config.Config, port int, quiet bool) *Server {
+	s := &Server{
+		config: cfg,
+		loadConfig: func(sitePath string) (config.Config, error) {
+			return config.LoadConfigForEnvironment(sitePath, cfg.Environment)
+		},
+		port:  port,
+		quiet: quiet,
+	}
+	s.builder = s.newBuilder(cfg)
+	return s
+}
+
+// SetConfigLoader sets how the config is loaded again when a config file
+// changes, which is the config of the server's environment by default
+func (s *Server) SetConfigLoader(load func(sitePath string) (config.Config, error)) {
+	s.loadConfig = load
+}
+
+// newBuilder creates the builder of the site for cfg
+func (s *Server) newBuilder(cfg config.Config) *build.Builder {
 	builder := build.NewBuilder(cfg)
-	builder.SetQuiet(quiet)
+	builder.SetQuiet(s.quiet)
 	// Enable development mode for the builder, which disables template caching
 	// This ensures templates are reloaded when changed during development
 	builder.SetDevMode(true)
-	
-	return &Server{
-		config:  cfg,
-		builder: builder,
-		port:    port,
-		quiet:   quiet,
+	// Keep output readable while developing, regardless of the config
+	builder.SetMinify(false)
+	return builder
+}
+
+// rebuild builds the site again after files changed, reloading the config
+// first when one of them is a config file
+func (s *Server) rebuild(sitePath string, changed []string) error {
+	for _, file := range changed {
+		if config.IsConfigFile(sitePath, file) {
+			if err := s.reloadConfig(sitePath); err != nil {
+				return err
+			}
+			break
+		}
 	}
+
+	// Let build hooks know what triggered this rebuild
+	s.builder.SetChangedFiles(changed)
+	return s.builder.Build(sitePath)
 }
 
-// Start starts the development server
-func (s *Server) Start(sitePath string) error {
-	// Create output directory if it doesn't exist
+// reloadConfig loads the config again and replaces the builder with one
+// for it. A new outputDir or base path in baseURL is served from then on.
+func (s *Server) reloadConfig(sitePath string) error {
+	cfg, err := s.loadConfig(sitePath)
+	if err != nil {
+		return err
+	}
+	if !s.quiet {
+		fmt.Println("Config changed, reloading...")
+	}
+
+	moved := cfg.OutputDir != s.config.OutputDir || cfg.BasePath() != s.config.BasePath()
+	s.config = cfg
+	s.builder = s.newBuilder(cfg)
+	if moved {
+		if err := s.mount(sitePath); err != nil {
+			return err
+		}
+		// Always show where the site went
+		fmt.Printf("Server now running at http://localhost:%d%s\n", s.port, cfg.BasePath())
+	}
+	return nil
+}
+
+// mount serves the output directory of the current config at its base
+// path, creating the directory if it doesn't exist
+func (s *Server) mount(sitePath string) error {
 	outputPath := filepath.Join(sitePath, s.config.OutputDir)
 	if err := os.MkdirAll(outputPath, 0755); err != nil {
 		return err
 	}
 
+	handler := s.handler(outputPath)
+	s.mu.Lock()
+	s.mounted = handler
+	s.mu.Unlock()
+	return nil
+}
+
+// ServeHTTP serves the site as mounted for the current config
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	handler := s.mounted
+	s.mu.RUnlock()
+	handler.ServeHTTP(w, r)
+}
+
+// Start starts the development server
+func (s *Server) Start(sitePath string) error {
+	// Serve the output directory, creating it if it doesn't exist
+	if err := s.mount(sitePath); err != nil {
+		return err
+	}
+	basePath := s.config.BasePath()
+
 	// Show initial message
 	if !s.quiet {
 		fmt.Printf("Starting development server for site from '%s'...\n", sitePath)
@@ -56,13 +145,13 @@ func (s *Server) Start(sitePath string) error {
 	}
 
 	// Create watcher with quiet mode
-	s.watcher = build.NewWatcher(s.builder, sitePath)
+	s.watcher = build.NewWatcher(sitePath)
 	s.watcher.SetQuiet(s.quiet)
 
 	// Start file watcher in background
 	go func() {
-		err := s.watcher.Watch(time.Second, func() error {
-			return s.builder.Build(sitePath)
+		err := s.watcher.Watch(time.Second, func(changed []string) error {
+			return s.rebuild(sitePath, changed)
 		})
 		if err != nil {
 			log.Printf("Watcher error: %s\n", err)
@@ -70,8 +159,64 @@ func (s *Server) Start(sitePath string) error {
 	}()
 
 	// Start HTTP server - always show these minimal messages
-	fmt.Printf("Server running at http://localhost:%d/\n", s.port)
+	fmt.Printf("Server running at http://localhost:%d%s\n", s.port, basePath)
 	fmt.Println("Watching for changes. Press Ctrl+C to stop.")
 	
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), http.FileServer(http.Dir(outputPath)))
+	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), s)
+}
+
+// handler serves the output directory at the base path of BaseURL, so
+// URLs behave the same locally as in production
+func (s *Server) handler(outputPath string) http.Handler {
+	fileServer := notFoundHandler(outputPath)
+
+	basePath := s.config.BasePath()
+	if basePath == "/" {
+		return fileServer
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(basePath, http.StripPrefix(strings.TrimSuffix(basePath, "/"), fileServer))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// Send visitors of the bare host to the site
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, basePath, http.StatusFound)
+			return
+		}
+		serveNotFound(w, r, outputPath)
+	})
+	return mux
+}
+
+// notFoundHandler serves the files in outputPath, answering requests for
+// missing files with the site's 404 page
+func notFoundHandler(outputPath string) http.Handler {
+	root := http.Dir(outputPath)
+	fileServer := http.FileServer(root)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := root.Open(path.Clean("/" + r.URL.Path))
+		if err != nil && os.IsNotExist(err) {
+			serveNotFound(w, r, outputPath)
+			return
+		}
+		if err == nil {
+			f.Close()
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// serveNotFound writes 404.html from outputPath with a 404 status, or a
+// plain error when the site has none
+func serveNotFound(w http.ResponseWriter, r *http.Request, outputPath string) {
+	page, err := os.ReadFile(filepath.Join(outputPath, "404.html"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write(page)
 }
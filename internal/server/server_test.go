@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dikaio/scribe/internal/config"
@@ -136,4 +137,177 @@ func TestCreateOutputDir(t *testing.T) {
 	if os.IsNotExist(err) {
 		t.Errorf("Expected output directory to exist")
 	}
-}
\ No newline at end of file
+}
+
+func TestHandlerBasePath(t *testing.T) {
+	publicDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(publicDir, "about"), 0755); err != nil {
+		t.Fatalf("Failed to create about dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(publicDir, "about", "index.html"), []byte("About"), 0644); err != nil {
+		t.Fatalf("Failed to write about page: %v", err)
+	}
+
+	s := NewServer(config.Config{BaseURL: "https://example.com/docs/", OutputDir: "public"}, 8080, true)
+	handler := s.handler(publicDir)
+
+	tests := []struct {
+		path     string
+		code     int
+		location string
+	}{
+		{"/docs/about/", http.StatusOK, ""},
+		{"/", http.StatusFound, "/docs/"},
+		{"/about/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+		if rr.Code != tt.code {
+			t.Errorf("%s: Expected status %d, got %d", tt.path, tt.code, rr.Code)
+		}
+		if tt.location != "" && rr.Header().Get("Location") != tt.location {
+			t.Errorf("%s: Expected redirect to %s, got %s", tt.path, tt.location, rr.Header().Get("Location"))
+		}
+	}
+}
+
+func TestNotFoundPage(t *testing.T) {
+	publicDir := t.TempDir()
+	notFound := "<html><body>Not here</body></html>"
+	if err := os.WriteFile(filepath.Join(publicDir, "404.html"), []byte(notFound), 0644); err != nil {
+		t.Fatalf("Failed to write 404.html: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(publicDir, "index.html"), []byte("Home"), 0644); err != nil {
+		t.Fatalf("Failed to write index.html: %v", err)
+	}
+
+	for _, baseURL := range []string{"https://example.com/", "https://example.com/docs/"} {
+		s := NewServer(config.Config{BaseURL: baseURL, OutputDir: "public"}, 8080, true)
+		handler := s.handler(publicDir)
+
+		for _, p := range []string{"/missing/", "/docs/missing.css"} {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", p, nil))
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("%s%s: Expected status 404, got %d", baseURL, p, rr.Code)
+			}
+			if rr.Body.String() != notFound {
+				t.Errorf("%s%s: Expected the 404 page, got %q", baseURL, p, rr.Body.String())
+			}
+		}
+	}
+}
+
+func TestRebuildReloadsConfig(t *testing.T) {
+	sitePath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(sitePath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("config.yml", "title: Before\n")
+	writeFile(filepath.Join("content", "about.md"), "---\ntitle: About\n---\nHello\n")
+	layouts := filepath.Join("themes", "default", "layouts")
+	writeFile(filepath.Join(layouts, "base.html"), `<title>{{.Site.Title}}</title>{{block "content" .}}{{end}}`)
+	for _, name := range []string{"single.html", "page.html", "list.html", "home.html"} {
+		writeFile(filepath.Join(layouts, name), `{{define "content"}}{{.Content}}{{end}}`)
+	}
+
+	cfg, err := config.LoadConfigForEnvironment(sitePath, "staging")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	s := NewServer(cfg, 8080, true)
+
+	// Content changes keep the config
+	if err := s.rebuild(sitePath, []string{filepath.Join(sitePath, "content", "about.md")}); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	if s.config.Title != "Before" {
+		t.Errorf("Expected title 'Before', got '%s'", s.config.Title)
+	}
+
+	// Changes to the environment's overlay reload it
+	overlay := filepath.Join("config", "staging", "config.yml")
+	writeFile(overlay, "title: After\n")
+	builder := s.builder
+	if err := s.rebuild(sitePath, []string{filepath.Join(sitePath, overlay)}); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	if s.config.Title != "After" {
+		t.Errorf("Expected title 'After' from the staging overlay, got '%s'", s.config.Title)
+	}
+	if s.config.Environment != "staging" {
+		t.Errorf("Expected environment 'staging', got '%s'", s.config.Environment)
+	}
+	if s.builder == builder {
+		t.Error("Expected a new builder for the reloaded config")
+	}
+	home, err := os.ReadFile(filepath.Join(sitePath, "public", "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read home page: %v", err)
+	}
+	if !strings.Contains(string(home), "<title>After</title>") {
+		t.Errorf("Expected the home page to be built with the new title, got %s", home)
+	}
+}
+
+func TestReloadConfigRemounts(t *testing.T) {
+	sitePath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(sitePath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("config.yml", "title: Docs\nbaseURL: https://example.com/docs/\n")
+	writeFile(filepath.Join("content", "about.md"), "---\ntitle: About\n---\nHello\n")
+	layouts := filepath.Join("themes", "default", "layouts")
+	writeFile(filepath.Join(layouts, "base.html"), `<title>{{.Site.Title}}</title>{{block "content" .}}{{end}}`)
+	for _, name := range []string{"single.html", "page.html", "list.html", "home.html"} {
+		writeFile(filepath.Join(layouts, name), `{{define "content"}}{{.Content}}{{end}}`)
+	}
+
+	cfg, err := config.LoadConfigForEnvironment(sitePath, "development")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	s := NewServer(cfg, 8080, true)
+	if err := s.mount(sitePath); err != nil {
+		t.Fatalf("Failed to mount: %v", err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	if err := s.rebuild(sitePath, []string{filepath.Join(sitePath, "content", "about.md")}); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	if rr := get("/docs/about/"); rr.Code != http.StatusOK {
+		t.Errorf("Expected /docs/about/ to be served, got status %d", rr.Code)
+	}
+
+	// Moving the site serves it at its new place
+	writeFile("config.yml", "title: Blog\nbaseURL: https://example.com/blog/\noutputDir: dist\n")
+	if err := s.rebuild(sitePath, []string{filepath.Join(sitePath, "config.yml")}); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	rr := get("/blog/about/")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "<title>Blog</title>") {
+		t.Errorf("Expected /blog/about/ to be served from dist, got status %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := get("/docs/about/"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected the old base path to be gone, got status %d", rr.Code)
+	}
+}
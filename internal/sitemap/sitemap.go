@@ -12,25 +12,61 @@ import (
 	"github.com/dikaio/scribe/internal/content"
 )
 
+// maxURLs is the most URLs a single sitemap file may hold
+const maxURLs = 50000
+
 // URLSet represents the root element of a sitemap
 type URLSet struct {
-	XMLName xml.Name `xml:"urlset"`
-	XMLNS   string   `xml:"xmlns,attr"`
-	URLs    []URL    `xml:"url"`
+	XMLName    xml.Name `xml:"urlset"`
+	XMLNS      string   `xml:"xmlns,attr"`
+	XMLNSXHTML string   `xml:"xmlns:xhtml,attr,omitempty"`
+	URLs       []URL    `xml:"url"`
 }
 
 // URL represents a URL entry in the sitemap
 type URL struct {
-	Loc        string  `xml:"loc"`
-	LastMod    string  `xml:"lastmod,omitempty"`
-	ChangeFreq string  `xml:"changefreq,omitempty"`
-	Priority   float64 `xml:"priority,omitempty"`
+	Loc        string      `xml:"loc"`
+	LastMod    string      `xml:"lastmod,omitempty"`
+	ChangeFreq string      `xml:"changefreq,omitempty"`
+	Priority   float64     `xml:"priority,omitempty"`
+	Alternates []Alternate `xml:"xhtml:link"`
+}
+
+// Alternate links a URL to a translation of the page
+type Alternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// SitemapIndex represents the root element of a sitemap index, which
+// lists the files of a sitemap split because of its size
+type SitemapIndex struct {
+	XMLName  xml.Name  `xml:"sitemapindex"`
+	XMLNS    string    `xml:"xmlns,attr"`
+	Sitemaps []Sitemap `xml:"sitemap"`
+}
+
+// Sitemap represents a sitemap file in a sitemap index
+type Sitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// listPage is a generated list page, such as a tag page
+type listPage struct {
+	permalink string
+	pages     []content.Page
 }
 
 // Generator handles sitemap generation
 type Generator struct {
 	config  config.Config
 	baseURL string
+	minify  bool
+	lists   []listPage
+	maxURLs int
+	root    string
 }
 
 // NewGenerator creates a new sitemap generator
@@ -44,36 +80,64 @@ func NewGenerator(cfg config.Config) *Generator {
 	return &Generator{
 		config:  cfg,
 		baseURL: baseURL,
+		minify:  cfg.Minify,
+		maxURLs: maxURLs,
+		root:    "sitemap.xml",
 	}
 }
 
-// Generate creates a sitemap.xml file from a list of pages
+// SetMinify controls whether the sitemap is written without indentation
+func (g *Generator) SetMinify(enabled bool) {
+	g.minify = enabled
+}
+
+// AddList adds a list page, such as a tag or section page, to the sitemap.
+// Its lastmod is that of the most recently modified page it lists.
+func (g *Generator) AddList(permalink string, pages []content.Page) {
+	g.lists = append(g.lists, listPage{permalink: permalink, pages: pages})
+}
+
+// Root returns the file name crawlers should be pointed at after Generate:
+// sitemap_index.xml when the sitemap was split, otherwise the sitemap
+func (g *Generator) Root() string {
+	return g.root
+}
+
+// Generate creates a sitemap.xml file from a list of pages. Sitemaps with
+// more than 50,000 URLs are split into numbered files next to it, listed
+// in sitemap_index.xml.
 func (g *Generator) Generate(pages []content.Page, outputPath string) error {
-	// Create sitemap structure
-	urlset := URLSet{
-		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		URLs:  make([]URL, 0, len(pages)+1), // +1 for the homepage
-	}
+	urls := make([]URL, 0, len(pages)+len(g.lists)+1) // +1 for the homepage
 
-	// Add homepage
-	urlset.URLs = append(urlset.URLs, URL{
+	// Add homepage, last modified with the newest page
+	urls = append(urls, URL{
 		Loc:        g.baseURL,
-		LastMod:    time.Now().Format("2006-01-02"),
+		LastMod:    formatLastmod(latestLastmod(pages)),
 		ChangeFreq: "daily",
 		Priority:   1.0,
 	})
 
+	// Translations share a translation key
+	translations := make(map[string][]content.Page)
+	for _, page := range pages {
+		if page.TranslationKey != "" && !page.Draft && !page.Sitemap.Exclude {
+			translations[page.TranslationKey] = append(translations[page.TranslationKey], page)
+		}
+	}
+
+	hasAlternates := false
+
 	// Add pages
 	for _, page := range pages {
-		// Skip draft pages
-		if page.Draft {
+		// Skip draft pages and pages excluded in front matter
+		if page.Draft || page.Sitemap.Exclude {
 			continue
 		}
 
 		// Create URL entry
 		url := URL{
 			Loc:     page.Permalink,
-			LastMod: page.Date.Format("2006-01-02"),
+			LastMod: formatLastmod(pageLastmod(page)),
 		}
 
 		// Set different priorities and change frequencies based on content type
@@ -85,7 +149,36 @@ func (g *Generator) Generate(pages []content.Page, outputPath string) error {
 			url.Priority = 0.5
 		}
 
-		urlset.URLs = append(urlset.URLs, url)
+		// Front matter overrides the defaults
+		if page.Sitemap.ChangeFreq != "" {
+			url.ChangeFreq = page.Sitemap.ChangeFreq
+		}
+		if page.Sitemap.Priority > 0 {
+			url.Priority = page.Sitemap.Priority
+		}
+
+		if group := translations[page.TranslationKey]; len(group) > 1 {
+			for _, translation := range group {
+				url.Alternates = append(url.Alternates, Alternate{
+					Rel:      "alternate",
+					Hreflang: g.lang(translation),
+					Href:     translation.Permalink,
+				})
+			}
+			hasAlternates = true
+		}
+
+		urls = append(urls, url)
+	}
+
+	// Add list pages
+	for _, list := range g.lists {
+		urls = append(urls, URL{
+			Loc:        list.permalink,
+			LastMod:    formatLastmod(latestLastmod(list.pages)),
+			ChangeFreq: "weekly",
+			Priority:   0.5,
+		})
 	}
 
 	// Ensure the directory exists
@@ -94,6 +187,53 @@ func (g *Generator) Generate(pages []content.Page, outputPath string) error {
 		return fmt.Errorf("failed to create directory for sitemap: %w", err)
 	}
 
+	newURLSet := func(urls []URL) URLSet {
+		urlset := URLSet{
+			XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+			URLs:  urls,
+		}
+		if hasAlternates {
+			urlset.XMLNSXHTML = "http://www.w3.org/1999/xhtml"
+		}
+		return urlset
+	}
+
+	if len(urls) <= g.maxURLs {
+		g.root = filepath.Base(outputPath)
+		return g.writeXML(outputPath, newURLSet(urls))
+	}
+
+	// Split into sitemap1.xml, sitemap2.xml, ... next to outputPath
+	ext := filepath.Ext(outputPath)
+	name := strings.TrimSuffix(filepath.Base(outputPath), ext)
+	index := SitemapIndex{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for i := 0; i*g.maxURLs < len(urls); i++ {
+		end := (i + 1) * g.maxURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+		chunk := urls[i*g.maxURLs : end]
+
+		file := fmt.Sprintf("%s%d%s", name, i+1, ext)
+		if err := g.writeXML(filepath.Join(dir, file), newURLSet(chunk)); err != nil {
+			return err
+		}
+
+		var lastmod string
+		for _, url := range chunk {
+			if url.LastMod > lastmod {
+				lastmod = url.LastMod
+			}
+		}
+		index.Sitemaps = append(index.Sitemaps, Sitemap{Loc: g.baseURL + file, LastMod: lastmod})
+	}
+
+	g.root = name + "_index" + ext
+	return g.writeXML(filepath.Join(dir, g.root), index)
+}
+
+// writeXML writes v as an XML document to outputPath
+func (g *Generator) writeXML(outputPath string, v interface{}) error {
 	// Create output file
 	f, err := os.Create(outputPath)
 	if err != nil {
@@ -106,10 +246,51 @@ func (g *Generator) Generate(pages []content.Page, outputPath string) error {
 
 	// Encode and write the sitemap
 	encoder := xml.NewEncoder(f)
-	encoder.Indent("", "  ")
-	if err := encoder.Encode(urlset); err != nil {
+	if !g.minify {
+		encoder.Indent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
 		return fmt.Errorf("failed to encode sitemap: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// lang returns the language of a page, defaulting to the site language
+func (g *Generator) lang(page content.Page) string {
+	if page.Lang != "" {
+		return page.Lang
+	}
+	return g.config.Language
+}
+
+// pageLastmod returns when a page was last modified, or its date when
+// that is unknown
+func pageLastmod(page content.Page) time.Time {
+	if !page.Lastmod.IsZero() {
+		return page.Lastmod
+	}
+	return page.Date
+}
+
+// latestLastmod returns the newest lastmod of the published pages
+func latestLastmod(pages []content.Page) time.Time {
+	var latest time.Time
+	for _, page := range pages {
+		if page.Draft {
+			continue
+		}
+		if lastmod := pageLastmod(page); lastmod.After(latest) {
+			latest = lastmod
+		}
+	}
+	return latest
+}
+
+// formatLastmod formats a lastmod date, or returns "" when it is unknown
+func formatLastmod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
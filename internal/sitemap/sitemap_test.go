@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -175,4 +176,122 @@ func TestBaseURLHandling(t *testing.T) {
 	if generator.baseURL != "https://example.com/" {
 		t.Errorf("Expected baseURL to have trailing slash, got %s", generator.baseURL)
 	}
-}
\ No newline at end of file
+}
+func TestSitemapSettings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := config.Config{BaseURL: "https://example.com/", Language: "en"}
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pages := []content.Page{
+		{
+			Permalink: "https://example.com/about/",
+			Date:      date,
+			Lastmod:   time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			Sitemap:   content.SitemapSettings{Priority: 0.9, ChangeFreq: "yearly"},
+		},
+		{
+			Permalink: "https://example.com/secret/",
+			Date:      date,
+			Sitemap:   content.SitemapSettings{Exclude: true},
+		},
+		{
+			Permalink:      "https://example.com/posts/hello/",
+			Date:           date,
+			IsPost:         true,
+			TranslationKey: "hello",
+			Tags:           []string{"intro"},
+		},
+		{
+			Permalink:      "https://example.com/fr/posts/bonjour/",
+			Date:           date,
+			IsPost:         true,
+			Lang:           "fr",
+			TranslationKey: "hello",
+		},
+	}
+
+	generator := NewGenerator(cfg)
+	generator.AddList("https://example.com/tags/intro/", pages[2:3])
+
+	outputPath := filepath.Join(tempDir, "sitemap.xml")
+	if err := generator.Generate(pages, outputPath); err != nil {
+		t.Fatalf("Failed to generate sitemap: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated sitemap: %v", err)
+	}
+	sitemap := string(data)
+
+	for _, want := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		// The home page is as recent as the newest page
+		"<loc>https://example.com/</loc>\n    <lastmod>2024-03-05</lastmod>",
+		"<loc>https://example.com/about/</loc>\n    <lastmod>2024-03-05</lastmod>\n    <changefreq>yearly</changefreq>\n    <priority>0.9</priority>",
+		"<loc>https://example.com/tags/intro/</loc>\n    <lastmod>2024-01-01</lastmod>",
+		`<xhtml:link rel="alternate" hreflang="en" href="https://example.com/posts/hello/"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="fr" href="https://example.com/fr/posts/bonjour/"></xhtml:link>`,
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("Expected sitemap to contain %q, got:\n%s", want, sitemap)
+		}
+	}
+	if strings.Contains(sitemap, "secret") {
+		t.Errorf("Expected excluded page to be left out, got:\n%s", sitemap)
+	}
+	if generator.Root() != "sitemap.xml" {
+		t.Errorf("Expected root sitemap.xml, got %s", generator.Root())
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg := config.Config{BaseURL: "https://example.com/"}
+	pages := []content.Page{
+		{Permalink: "https://example.com/a/", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Permalink: "https://example.com/b/", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Permalink: "https://example.com/c/", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	generator := NewGenerator(cfg)
+	generator.maxURLs = 3
+
+	// The home page makes four URLs, one more than fits in a file
+	if err := generator.Generate(pages, filepath.Join(tempDir, "sitemap.xml")); err != nil {
+		t.Fatalf("Failed to generate sitemap: %v", err)
+	}
+	if generator.Root() != "sitemap_index.xml" {
+		t.Errorf("Expected root sitemap_index.xml, got %s", generator.Root())
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "sitemap_index.xml"))
+	if err != nil {
+		t.Fatalf("Expected sitemap index to be written: %v", err)
+	}
+	var index SitemapIndex
+	if err := xml.Unmarshal(data, &index); err != nil {
+		t.Fatalf("Failed to parse sitemap index: %v", err)
+	}
+	if len(index.Sitemaps) != 2 {
+		t.Fatalf("Expected 2 sitemaps in the index, got %d", len(index.Sitemaps))
+	}
+	if index.Sitemaps[1].Loc != "https://example.com/sitemap2.xml" {
+		t.Errorf("Expected second sitemap at https://example.com/sitemap2.xml, got %s", index.Sitemaps[1].Loc)
+	}
+
+	for file, count := range map[string]int{"sitemap1.xml": 3, "sitemap2.xml": 1} {
+		data, err := os.ReadFile(filepath.Join(tempDir, file))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", file, err)
+		}
+		var urlset URLSet
+		if err := xml.Unmarshal(data, &urlset); err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+		if len(urlset.URLs) != count {
+			t.Errorf("Expected %d URLs in %s, got %d", count, file, len(urlset.URLs))
+		}
+	}
+}
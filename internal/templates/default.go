@@ -7,12 +7,14 @@ import (
 
 var (
 	// Default template strings
-	BaseTemplate   string
-	SingleTemplate string
-	ListTemplate   string
-	HomeTemplate   string
-	PageTemplate   string
-	StyleCSS       string
+	BaseTemplate     string
+	SingleTemplate   string
+	ListTemplate     string
+	HomeTemplate     string
+	PageTemplate     string
+	NotFoundTemplate string
+	SearchPartial    string
+	StyleCSS         string
 
 	// Initialization once
 	defaultTemplatesOnce sync.Once
@@ -47,6 +49,16 @@ func loadDefaultTemplates() {
 		log.Printf("Warning: Failed to load embedded page template: %v", err)
 	}
 
+	NotFoundTemplate, err = GetDefaultTemplate("404.html")
+	if err != nil {
+		log.Printf("Warning: Failed to load embedded 404 template: %v", err)
+	}
+
+	SearchPartial, err = GetDefaultTemplate("partials/search.html")
+	if err != nil {
+		log.Printf("Warning: Failed to load embedded search partial: %v", err)
+	}
+
 	StyleCSS, err = GetDefaultTemplate("style.css")
 	if err != nil {
 		log.Printf("Warning: Failed to load embedded CSS: %v", err)
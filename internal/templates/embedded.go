@@ -67,4 +67,25 @@ func getAllTemplates(theme string) (map[string]string, error) {
 	}
 	
 	return templates, nil
-}
\ No newline at end of file
+}
+
+// GetInternalTemplates returns the built-in templates that every layout can
+// include, keyed by name, e.g. "_internal/opengraph.html"
+func GetInternalTemplates() (map[string]string, error) {
+	templates := make(map[string]string)
+
+	entries, err := fs.ReadDir(EmbeddedFS, "embedded/_internal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded internal templates: %w", err)
+	}
+
+	for _, entry := range entries {
+		content, err := EmbeddedFS.ReadFile("embedded/_internal/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		templates["_internal/"+entry.Name()] = string(content)
+	}
+
+	return templates, nil
+}
@@ -26,6 +26,12 @@ func TestDefaultTemplates(t *testing.T) {
 		"sub": func(a, b int) int {
 			return a - b 
 		},
+		"relURL": func(path string) string {
+			return "/" + path
+		},
+		"relLangURL": func(path string) string {
+			return "/" + path
+		},
 	})
 	
 	// Add BaseTemplate
@@ -37,10 +43,16 @@ func TestDefaultTemplates(t *testing.T) {
 	
 	// Add other templates
 	templateStrings := map[string]string{
-		"SingleTemplate": SingleTemplate,
-		"ListTemplate":   ListTemplate,
-		"HomeTemplate":   HomeTemplate,
-		"PageTemplate":   PageTemplate,
+		"SingleTemplate":   SingleTemplate,
+		"ListTemplate":     ListTemplate,
+		"HomeTemplate":     HomeTemplate,
+		"PageTemplate":     PageTemplate,
+		"NotFoundTemplate": NotFoundTemplate,
+	}
+
+	// Partials are named by their path, as the renderer names them
+	if _, err = tmpl.New("partials/search.html").Parse(SearchPartial); err != nil {
+		t.Fatalf("Failed to parse SearchPartial: %v", err)
 	}
 	
 	for name, templateString := range templateStrings {
@@ -68,4 +80,25 @@ func TestSampleContent(t *testing.T) {
 	if SamplePage == "" {
 		t.Error("SamplePage is empty")
 	}
-}
\ No newline at end of file
+}
+
+func TestInternalTemplates(t *testing.T) {
+	internal, err := GetInternalTemplates()
+	if err != nil {
+		t.Fatalf("Failed to load internal templates: %v", err)
+	}
+
+	for _, name := range []string{"_internal/opengraph.html", "_internal/twitter_cards.html", "_internal/canonical.html", "_internal/schema.html"} {
+		text, ok := internal[name]
+		if !ok {
+			t.Errorf("Expected internal template %s", name)
+			continue
+		}
+		tmpl := template.New(name).Funcs(template.FuncMap{
+			"absURL": func(path string) string { return "https://example.com/" + path },
+		})
+		if _, err := tmpl.Parse(text); err != nil {
+			t.Errorf("Failed to parse %s: %v", name, err)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/dikaio/scribe/internal/build"
 	"github.com/dikaio/scribe/internal/config"
 	"github.com/dikaio/scribe/internal/content"
+	"github.com/dikaio/scribe/internal/linkcheck"
 	"github.com/dikaio/scribe/internal/server"
 	"github.com/dikaio/scribe/internal/templates"
 )
@@ -69,6 +70,20 @@ func (a *App) registerCommands() {
 		Action:      a.cmdBuild,
 	}
 
+	// Check command for validating a built site
+	a.Commands["check"] = Command{
+		Name:        "check",
+		Description: "Check a built site for broken links",
+		Action:      a.cmdCheck,
+	}
+
+	// Config command for editing the site configuration
+	a.Commands["config"] = Command{
+		Name:        "config",
+		Description: "Show or change the site configuration",
+		Action:      a.cmdConfig,
+	}
+
 	// New command for site and page creation
 	a.Commands["new"] = Command{
 		Name:        "new",
@@ -123,6 +138,14 @@ func (a *App) showHelp() {
 	fmt.Printf("  %s new page [path]      Create a new page at the specified path\n", a.Name)
 	fmt.Printf("  %s serve                Start development server for the current directory\n", a.Name)
 	fmt.Printf("  %s build                Build the static site in the current directory\n", a.Name)
+	fmt.Printf("  %s build --minify       Build the site with minified HTML, CSS, JS and XML\n", a.Name)
+	fmt.Printf("  %s build --checkLinks   Build the site and check it for broken links\n", a.Name)
+	fmt.Printf("  %s build --searchIndex  Build the site with a search index (or --searchIndex title,url,summary)\n", a.Name)
+	fmt.Printf("  %s build --environment staging  Build with the config for an environment (or SCRIBE_ENV)\n", a.Name)
+	fmt.Printf("  %s check links          Check the built site for broken links\n", a.Name)
+	fmt.Printf("  %s config               Print the configuration and where each value was set\n", a.Name)
+	fmt.Printf("  %s config get outputDir  Print one setting (add --format=json or yaml for scripts)\n", a.Name)
+	fmt.Printf("  %s config set title \"My Blog\"  Change a setting, keeping the config file's comments\n", a.Name)
 
 	fmt.Println("\nUse 'scribe --help' to display this help information.")
 }
@@ -130,8 +153,9 @@ func (a *App) showHelp() {
 // Command implementations
 
 // getSitePathAndConfig is a helper function to determine the site path from args
-// and load the site configuration. It standardizes this common operation across commands.
-func (a *App) getSitePathAndConfig(args []string, action string) (string, config.Config, error) {
+// and load the site configuration for an environment. It standardizes this common
+// operation across commands.
+func (a *App) getSitePathAndConfig(args []string, action, environment string) (string, config.Config, error) {
 	// Determine site path
 	sitePath := "."
 	if len(args) > 0 {
@@ -143,24 +167,47 @@ func (a *App) getSitePathAndConfig(args []string, action string) (string, config
 	}
 
 	// Load the site configuration
-	cfg, err := config.LoadConfig(sitePath)
+	cfg, err := loadSiteConfig(sitePath, environment)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return "", cfg, fmt.Errorf("config file not found in '%s', make sure this is a valid Scribe site directory", sitePath)
-		}
-		return "", cfg, fmt.Errorf("failed to load configuration: %w", err)
+		return "", cfg, err
+	}
+
+	// Unknown keys are most likely typos, but don't stop the build
+	for _, warning := range cfg.Warnings() {
+		Warning(warning)
 	}
 
 	return sitePath, cfg, nil
 }
 
+// loadSiteConfig loads the configuration of the site at sitePath for an
+// environment, explaining a missing config file
+func loadSiteConfig(sitePath, environment string) (config.Config, error) {
+	cfg, err := config.LoadConfigForEnvironment(sitePath, environment)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, fmt.Errorf("config file not found in '%s', make sure this is a valid Scribe site directory", sitePath)
+		}
+		return cfg, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
 // cmdBuild implements the build command, which generates the static site.
 // It takes an optional path argument (or uses the current directory if not provided).
 func (a *App) cmdBuild(args []string) error {
-	sitePath, cfg, err := a.getSitePathAndConfig(args, "Building")
+	args, flags := parseFlags(args, "standIn", "environment", "searchIndex")
+
+	sitePath, cfg, err := a.getSitePathAndConfig(args, "Building", environment(flags, "production"))
 	if err != nil {
 		return err
 	}
+	if err := cfg.ValidateSite(sitePath); err != nil {
+		return err
+	}
+
+	// Command line flags override the site configuration
+	applyBuildFlags(&cfg, flags)
 
 	// Initialize the builder
 	builder := build.NewBuilder(cfg)
@@ -178,16 +225,147 @@ func (a *App) cmdBuild(args []string) error {
 
 	buildTime := time.Since(start)
 	fmt.Printf("Site built successfully in %v! Output directory: '%s'\n", buildTime, cfg.OutputDir)
+
+	if flagEnabled(flags, "checkLinks") {
+		return checkLinks(sitePath, cfg, flags)
+	}
+	return nil
+}
+
+// cmdCheck implements the check command. "scribe check links" reports
+// broken links in the built site and fails if any are found.
+func (a *App) cmdCheck(args []string) error {
+	args, flags := parseFlags(args, "standIn", "environment")
+
+	if len(args) < 1 || args[0] != "links" {
+		fmt.Println("Usage:")
+		fmt.Println("  scribe check links [path]                   Check internal links and anchors")
+		fmt.Println("  scribe check links --external               Also request external links")
+		fmt.Println("  scribe check links --standIn=http://host    Check external links against a local server")
+		if len(args) > 0 {
+			return fmt.Errorf("unknown check: %s", args[0])
+		}
+		return nil
+	}
+
+	sitePath, cfg, err := a.getSitePathAndConfig(args[1:], "Checking", environment(flags, "production"))
+	if err != nil {
+		return err
+	}
+
+	return checkLinks(sitePath, cfg, flags)
+}
+
+// cmdConfig implements the config command. "scribe config" prints the
+// effective configuration with where each value was set, "scribe config
+// get" prints one key and "scribe config set" changes the config file.
+func (a *App) cmdConfig(args []string) error {
+	args, flags := parseFlags(args, "environment", "format", "searchIndex")
+
+	if len(args) > 0 && args[0] == "set" {
+		return a.configSet(args[1:])
+	}
+
+	key := ""
+	if len(args) > 0 && args[0] == "get" {
+		if len(args) < 2 {
+			return fmt.Errorf("config get requires a key")
+		}
+		key, args = args[1], args[2:]
+	}
+
+	sitePath := "."
+	if len(args) > 0 {
+		sitePath = args[0]
+	}
+
+	cfg, err := loadSiteConfig(sitePath, environment(flags, "production"))
+	if err != nil {
+		return err
+	}
+	applyBuildFlags(&cfg, flags)
+
+	// Warnings go to stderr so they don't end up in scripted output
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	return cfg.Print(os.Stdout, key, flags["format"])
+}
+
+// configSet sets a key in the config file in the site root
+func (a *App) configSet(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("config set requires a key and a value")
+	}
+
+	key, value := args[0], args[1]
+	sitePath := "."
+	if len(args) > 2 {
+		sitePath = args[2]
+	}
+
+	// Only the root config file is edited, so settings from config/
+	// overlays, SCRIBE_ variables and the theme aren't copied into it
+	cfg, err := config.LoadConfigFile(sitePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("config file not found in '%s', make sure this is a valid Scribe site directory", sitePath)
+		}
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Save(sitePath); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	Success(fmt.Sprintf("Set %s to %s", key, value))
+	return nil
+}
+
+// checkLinks checks the links of the site built at sitePath and returns an
+// error when any are broken, so CI runs fail
+func checkLinks(sitePath string, cfg config.Config, flags map[string]string) error {
+	options := linkcheck.Options{
+		BaseURL: cfg.BaseURL,
+		StandIn: flags["standIn"],
+	}
+	if flagEnabled(flags, "external") || options.StandIn != "" {
+		options.External = linkcheck.ExternalCheck
+	}
+
+	checker := linkcheck.NewChecker(filepath.Join(sitePath, cfg.OutputDir), options)
+	report, err := checker.Check()
+	if err != nil {
+		return err
+	}
+
+	report.Write(os.Stdout)
+	if count := report.Count(); count > 0 {
+		return fmt.Errorf("found %d broken links", count)
+	}
 	return nil
 }
 
 // cmdServe implements the serve command, which starts a development server with live reload.
 func (a *App) cmdServe(args []string) error {
+	args, flags := parseFlags(args, "environment", "searchIndex")
+
 	// Get site path and config
-	sitePath, cfg, err := a.getSitePathAndConfig(args, "")
+	sitePath, cfg, err := a.getSitePathAndConfig(args, "", environment(flags, "development"))
 	if err != nil {
 		return err
 	}
+	if err := cfg.ValidateSite(sitePath); err != nil {
+		return err
+	}
+	applySearchIndexFlag(&cfg, flags)
 
 	Info("Starting development server...")
 
@@ -195,6 +373,22 @@ func (a *App) cmdServe(args []string) error {
 	port := 8080
 	server := server.NewServer(cfg, port, false) // false = not quiet mode
 
+	// Config changes are loaded the same way, keeping the flags
+	server.SetConfigLoader(func(sitePath string) (config.Config, error) {
+		cfg, err := loadSiteConfig(sitePath, cfg.Environment)
+		if err != nil {
+			return cfg, err
+		}
+		for _, warning := range cfg.Warnings() {
+			Warning(warning)
+		}
+		if err := cfg.ValidateSite(sitePath); err != nil {
+			return cfg, err
+		}
+		applySearchIndexFlag(&cfg, flags)
+		return cfg, nil
+	})
+
 	// Start the server
 	err = server.Start(sitePath)
 	if err != nil {
@@ -26,7 +26,7 @@ func TestNewApp(t *testing.T) {
 	}
 
 	// Check that commands were registered
-	expectedCommands := []string{"serve", "new"}
+	expectedCommands := []string{"serve", "new", "check", "config"}
 	for _, cmd := range expectedCommands {
 		if _, exists := app.Commands[cmd]; !exists {
 			t.Errorf("Expected command '%s' to be registered", cmd)
@@ -45,7 +45,7 @@ func TestRegisterCommands(t *testing.T) {
 	app.registerCommands()
 
 	// Check that all expected commands are registered
-	expectedCommands := []string{"serve", "new"}
+	expectedCommands := []string{"serve", "new", "check"}
 	for _, cmd := range expectedCommands {
 		if _, exists := app.Commands[cmd]; !exists {
 			t.Errorf("Expected command '%s' to be registered", cmd)
@@ -201,6 +201,47 @@ func TestNewCommand(t *testing.T) {
 	}
 }
 
+func TestConfigCommand(t *testing.T) {
+	app := NewApp()
+	tempDir := t.TempDir()
+
+	configPath := filepath.Join(tempDir, "config.yml")
+	if err := os.WriteFile(configPath, []byte("# Blog settings\ntitle: Old # the name\n"), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	if err := app.cmdConfig([]string{"set", "title", "My Blog", tempDir}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if string(data) != "# Blog settings\ntitle: My Blog # the name\n" {
+		t.Errorf("Expected only the title to change, got:\n%s", data)
+	}
+
+	// Invalid values aren't saved
+	if err := app.cmdConfig([]string{"set", "baseURL", "example.com", tempDir}); err == nil {
+		t.Error("Expected an error for an invalid baseURL, got nil")
+	}
+	if err := app.cmdConfig([]string{"set", "title"}); err == nil || !strings.Contains(err.Error(), "requires a key and a value") {
+		t.Errorf("Expected an error about the missing value, got: %v", err)
+	}
+	if err := app.cmdConfig([]string{"get"}); err == nil || !strings.Contains(err.Error(), "requires a key") {
+		t.Errorf("Expected an error about the missing key, got: %v", err)
+	}
+	if err := app.cmdConfig([]string{"get", "outptDir", tempDir}); err == nil || !strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("Expected an error for an unknown key, got: %v", err)
+	}
+	if err := app.cmdConfig([]string{"get", "title", tempDir, "--format=json"}); err != nil {
+		t.Errorf("config get failed: %v", err)
+	}
+	if err := app.cmdConfig([]string{filepath.Join(tempDir, "missing")}); err == nil || !strings.Contains(err.Error(), "config file not found") {
+		t.Errorf("Expected an error for a missing site, got: %v", err)
+	}
+}
+
 func TestServeCommand(t *testing.T) {
 	// This is primarily a visual test as the serve command starts a server
 	// Create a test directory with minimal content to test the serve command
@@ -235,7 +276,7 @@ func TestServeCommand(t *testing.T) {
 	
 	// Create a simple test to see if parsing the config works
 	app := NewApp()
-	_, cfg, err := app.getSitePathAndConfig([]string{tempDir}, "")
+	_, cfg, err := app.getSitePathAndConfig([]string{tempDir}, "", "production")
 	if err != nil {
 		t.Fatalf("Failed to get site path and config: %v", err)
 	}
@@ -17,10 +17,16 @@ func (a *App) createDefaultTemplates(sitePath string, _ bool) error {
 		filepath.Join(sitePath, "themes", "default", "layouts", "list.html"):   templates.ListTemplate,
 		filepath.Join(sitePath, "themes", "default", "layouts", "home.html"):   templates.HomeTemplate,
 		filepath.Join(sitePath, "themes", "default", "layouts", "page.html"):   templates.PageTemplate,
+		filepath.Join(sitePath, "themes", "default", "layouts", "404.html"):    templates.NotFoundTemplate,
+
+		filepath.Join(sitePath, "themes", "default", "layouts", "partials", "search.html"): templates.SearchPartial,
 	}
 
 	// Write template files
 	for path, content := range templatePaths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for template '%s': %w", path, err)
+		}
 		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to create template '%s': %w", path, err)
 		}
@@ -54,6 +54,12 @@ func (a *App) createSiteEnhanced(initialName string) error {
 	// Set default values
 	cfg.Title = siteName
 	cfg.Description = fmt.Sprintf("A %s site created with Scribe", siteName)
+	cfg.Menus = map[string][]config.MenuEntry{
+		"main": {
+			{Name: "Home", URL: "/", Weight: 1},
+			{Name: "About", PageRef: "about.md", Weight: 2},
+		},
+	}
 	
 	if err := cfg.Save(sitePath); err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
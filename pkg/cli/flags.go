@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+// parseFlags separates "--name" and "--name=value" flags from positional
+// arguments. Flags listed in valueFlags may also take their value from the
+// following argument ("--name value"), unless it is another flag. Bare
+// flags are recorded as "true".
+func parseFlags(args []string, valueFlags ...string) ([]string, map[string]string) {
+	takesValue := make(map[string]bool, len(valueFlags))
+	for _, name := range valueFlags {
+		takesValue[name] = true
+	}
+
+	var positional []string
+	flags := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") || arg == "--" {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(arg, "--")
+		if idx := strings.Index(name, "="); idx >= 0 {
+			flags[name[:idx]] = name[idx+1:]
+			continue
+		}
+
+		if takesValue[name] && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			flags[name] = args[i+1]
+			i++
+			continue
+		}
+
+		flags[name] = "true"
+	}
+
+	return positional, flags
+}
+
+// flagEnabled reports whether a boolean flag was set to a truthy value
+func flagEnabled(flags map[string]string, name string) bool {
+	value, ok := flags[name]
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(value) {
+	case "false", "0", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+// applyBuildFlags applies the flags of the build command that override the
+// site configuration, recording them as the source of the keys they set
+func applyBuildFlags(cfg *config.Config, flags map[string]string) {
+	if _, ok := flags["minify"]; ok {
+		cfg.Minify = flagEnabled(flags, "minify")
+		cfg.SetSource("minify", "--minify")
+	}
+	applySearchIndexFlag(cfg, flags)
+}
+
+// applySearchIndexFlag applies --searchIndex to the configuration. A bare or
+// boolean value turns the index on or off; a comma separated list such as
+// "title,url,tags" turns it on with those fields.
+func applySearchIndexFlag(cfg *config.Config, flags map[string]string) {
+	value, ok := flags["searchIndex"]
+	if !ok {
+		return
+	}
+
+	cfg.SetSource("searchIndex.enable", "--searchIndex")
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "on", "false", "0", "no", "off":
+		cfg.SearchIndex.Enable = flagEnabled(flags, "searchIndex")
+		return
+	}
+
+	cfg.SearchIndex.Enable = true
+	cfg.SetSource("searchIndex.fields", "--searchIndex")
+	cfg.SearchIndex.Fields = nil
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			cfg.SearchIndex.Fields = append(cfg.SearchIndex.Fields, field)
+		}
+	}
+}
+
+// environment returns the environment selected with --environment or
+// SCRIBE_ENV, or fallback when neither is set
+func environment(flags map[string]string, fallback string) string {
+	if env := flags["environment"]; env != "" && env != "true" {
+		return env
+	}
+	if env := os.Getenv("SCRIBE_ENV"); env != "" {
+		return env
+	}
+	return fallback
+}
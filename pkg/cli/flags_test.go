@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dikaio/scribe/internal/config"
+)
+
+func TestParseFlags(t *testing.T) {
+	args, flags := parseFlags([]string{"my-site", "--minify", "--environment", "staging"}, "environment")
+
+	if len(args) != 1 || args[0] != "my-site" {
+		t.Errorf("Expected positional args [my-site], got %v", args)
+	}
+	if !flagEnabled(flags, "minify") {
+		t.Error("Expected --minify to be enabled")
+	}
+	if flags["environment"] != "staging" {
+		t.Errorf("Expected environment staging, got %q", flags["environment"])
+	}
+
+	// Explicitly disabled boolean flags
+	_, flags = parseFlags([]string{"--minify=false"})
+	if flagEnabled(flags, "minify") {
+		t.Error("Expected --minify=false to be disabled")
+	}
+}
+
+func TestApplySearchIndexFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		enable bool
+		fields []string
+	}{
+		{nil, false, []string{"content"}},
+		{[]string{"--searchIndex"}, true, []string{"content"}},
+		{[]string{"--searchIndex=false"}, false, []string{"content"}},
+		{[]string{"--searchIndex=title, url,tags"}, true, []string{"title", "url", "tags"}},
+		{[]string{"--searchIndex", "title,url"}, true, []string{"title", "url"}},
+		{[]string{"--searchIndex", "--minify"}, true, []string{"content"}},
+	}
+
+	for _, tt := range tests {
+		cfg := config.DefaultConfig()
+		cfg.SearchIndex.Fields = []string{"content"}
+
+		args, flags := parseFlags(tt.args, "searchIndex")
+		if len(args) != 0 {
+			t.Errorf("%v: Expected no positional args, got %v", tt.args, args)
+		}
+		applySearchIndexFlag(&cfg, flags)
+
+		if cfg.SearchIndex.Enable != tt.enable {
+			t.Errorf("%v: Expected enable %v, got %v", tt.args, tt.enable, cfg.SearchIndex.Enable)
+		}
+		if !reflect.DeepEqual(cfg.SearchIndex.Fields, tt.fields) {
+			t.Errorf("%v: Expected fields %v, got %v", tt.args, tt.fields, cfg.SearchIndex.Fields)
+		}
+	}
+}
+
+func TestApplyBuildFlags(t *testing.T) {
+	cfg := config.DefaultConfig()
+	_, flags := parseFlags([]string{"--minify", "--searchIndex=title,url"})
+	applyBuildFlags(&cfg, flags)
+
+	if !cfg.Minify {
+		t.Error("Expected --minify to enable minification")
+	}
+	for key, want := range map[string]string{
+		"minify":             "--minify",
+		"searchIndex.enable": "--searchIndex",
+		"searchIndex.fields": "--searchIndex",
+		"title":              "",
+	} {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("Expected the source of %s to be %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv("SCRIBE_ENV", "")
+	if got := environment(map[string]string{}, "development"); got != "development" {
+		t.Errorf("Expected the fallback environment, got %q", got)
+	}
+
+	t.Setenv("SCRIBE_ENV", "staging")
+	if got := environment(map[string]string{}, "development"); got != "staging" {
+		t.Errorf("Expected SCRIBE_ENV to select staging, got %q", got)
+	}
+
+	_, flags := parseFlags([]string{"--environment", "preview"}, "environment")
+	if got := environment(flags, "development"); got != "preview" {
+		t.Errorf("Expected --environment to override SCRIBE_ENV, got %q", got)
+	}
+}